@@ -0,0 +1,60 @@
+package linenoisy
+
+import (
+	"slices"
+	"unicode"
+)
+
+// abbrevExpansion is the last expansion of an abbreviation, which Ctrl-_ reverts.
+type abbrevExpansion struct {
+	start     int
+	abbr      []rune
+	expansion []rune
+}
+
+// expandAbbrev replaces the word before the cursor by its expansion in Abbrevs if it is
+// the first word of the line, where commands are typed, and reports whether it did.
+func (e *Terminal) expandAbbrev() bool {
+	start := e.Cur
+	for start > 0 && !unicode.IsSpace(e.Buffer[start-1]) {
+		start--
+	}
+	for _, r := range e.Buffer[:start] {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	x, ok := e.Abbrevs[string(e.Buffer[start:e.Cur])]
+	if !ok || start == e.Cur {
+		return false
+	}
+	exp := []rune(x)
+	if grow := len(exp) - (e.Cur - start); e.room(grow) < grow {
+		return false
+	}
+	e.expanded = &abbrevExpansion{start: start, abbr: slices.Clone(e.Buffer[start:e.Cur]), expansion: exp}
+	e.Buffer = slices.Replace(e.Buffer, start, e.Cur, exp...)
+	e.Cur = start + len(exp)
+	return true
+}
+
+// editInsertSpace types a space after expanding an abbreviation.
+func (e *Terminal) editInsertSpace() error {
+	e.expandAbbrev()
+	return e.editInsert(' ')
+}
+
+// editUndoExpansion puts back the abbreviation expanded by the previous key.
+func (e *Terminal) editUndoExpansion(x *abbrevExpansion) error {
+	if x == nil {
+		return e.beep()
+	}
+	end := x.start + len(x.expansion)
+	if end > len(e.Buffer) || !slices.Equal(e.Buffer[x.start:end], x.expansion) {
+		return e.beep()
+	}
+	e.Buffer = slices.Replace(e.Buffer, x.start, end, x.abbr...)
+	e.Cur += len(x.abbr) - len(x.expansion)
+	return e.refreshLine()
+}
@@ -0,0 +1,36 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestEditor_Abbrevs(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		line string
+	}{
+		{"gco main\x0d", "git checkout main"},
+		{"gco\x0d", "git checkout"},
+		{"  gco x\x0d", "  git checkout x"},
+		{"echo gco \x0d", "echo gco "},
+		{"gcox \x0d", "gcox "},
+		{"gco \x1f-b\x0d", "gco -b"},
+		{"gco x\x1f\x0d", "git checkout x"},
+	} {
+		e := &Terminal{
+			Inp:     bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:     bufio.NewWriter(&bytes.Buffer{}),
+			Prompt:  "> ",
+			Abbrevs: map[string]string{"gco": "git checkout"},
+		}
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+		}
+		if l != tt.line {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.line, l)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package linenoisy
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Size returns the width and the height of the terminal.
+func (e *Terminal) Size() (cols, rows int) {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	cols, rows = e.Cols, e.Rows
+	if cols == 0 {
+		cols = 80
+	}
+	if rows == 0 {
+		rows = 24
+	}
+	return cols, rows
+}
+
+// SetSize changes the dimensions of the terminal and repaints the line being edited.
+// Like WriteOut, it is safe to call from other goroutines, e.g. on window size reports. While the editor is busy,
+// for instance when it reads the second key of Ctrl-X and the read calls the OnResize of telnet, SetSize returns
+// right away and the size is applied as soon as the editor is done.
+func (e *Terminal) SetSize(cols, rows int) error {
+	if cols <= 0 || rows <= 0 {
+		return fmt.Errorf("invalid terminal size %dx%d", cols, rows)
+	}
+
+	e.sizeMu.Lock()
+	e.pendingSize = [2]int{cols, rows}
+	e.sizeMu.Unlock()
+
+	if !e.edit.TryLock() {
+		go func() {
+			e.edit.Lock()
+			defer e.edit.Unlock()
+
+			e.applySize()
+		}()
+		return nil
+	}
+	defer e.edit.Unlock()
+
+	return e.applySize()
+}
+
+// applySize sets the size of the last SetSize, unless an earlier call did already, and repaints.
+func (e *Terminal) applySize() error {
+	e.sizeMu.Lock()
+	size := e.pendingSize
+	e.pendingSize = [2]int{}
+	e.sizeMu.Unlock()
+
+	if size[0] == 0 {
+		return nil
+	}
+	e.Cols = size[0]
+	e.Rows = size[1]
+	if err := e.repaint(); err != nil {
+		return err
+	}
+	if !e.active || e.suspended {
+		return nil
+	}
+	return e.refreshStatusBar()
+}
+
+// CurrentPrompt returns the prompt.
+func (e *Terminal) CurrentPrompt() string {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	return e.Prompt
+}
+
+// SetPrompt changes the prompt and repaints the line being edited. Besides newlines, which start the lines
+// drawn above the input, the prompt may only hold printable text and escape sequences, like colors.
+// Like WriteOut, it is safe to call from other goroutines.
+func (e *Terminal) SetPrompt(prompt string) error {
+	if err := checkPrompt(prompt); err != nil {
+		return err
+	}
+
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	e.Prompt = prompt
+	return e.repaint()
+}
+
+// checkPrompt rejects invalid UTF-8 and the control characters which would move the cursor
+// behind the back of the width computations. ESC and BEL, which ends OSC sequences, are kept.
+func checkPrompt(prompt string) error {
+	if !utf8.ValidString(prompt) {
+		return fmt.Errorf("invalid UTF-8 in prompt %q", prompt)
+	}
+	for _, r := range prompt {
+		if unicode.IsControl(r) && r != esc && r != '\a' && r != '\n' && r != '\r' {
+			return fmt.Errorf("control character %U in prompt %q", r, prompt)
+		}
+	}
+	return nil
+}
+
+// Line returns the current user input.
+func (e *Terminal) Line() string {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	return string(e.Buffer)
+}
+
+// SetLine replaces the current user input, moves the cursor to its end and repaints it.
+// Like WriteOut, it is safe to call from other goroutines.
+func (e *Terminal) SetLine(line string) error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	e.Buffer = []rune(line)
+	e.Cur = len(e.Buffer)
+	return e.repaint()
+}
+
+// CursorPos returns the cursor position in the user input, in runes.
+func (e *Terminal) CursorPos() int {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	return min(max(e.Cur, 0), len(e.Buffer))
+}
+
+// SetCursorPos moves the cursor, clamped to the user input, and repaints it.
+// Like WriteOut, it is safe to call from other goroutines.
+func (e *Terminal) SetCursorPos(pos int) error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	e.Cur = min(max(pos, 0), len(e.Buffer))
+	return e.repaint()
+}
+
+// repaint redraws the edit region from scratch while LineEditor is running.
+func (e *Terminal) repaint() error {
+	if !e.active || e.suspended {
+		return nil
+	}
+	e.notZero()
+
+	ew := &errWriter{w: e.Out}
+	e.clearRegion(ew)
+	if ew.err != nil {
+		return ew.err
+	}
+
+	e.OldCur = 0
+	e.MaxRows = 0
+	e.curRow = 0
+	return e.refreshLine()
+}
@@ -0,0 +1,189 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Joker/linenoisy/telnet"
+)
+
+func TestEditor_Accessors(t *testing.T) {
+	e := &Terminal{Prompt: "> "}
+
+	if cols, rows := e.Size(); cols != 80 || rows != 24 {
+		t.Errorf("expected 80x24 got %dx%d", cols, rows)
+	}
+	if err := e.SetSize(0, 10); err == nil {
+		t.Error("err expected")
+	}
+	if err := e.SetSize(100, 30); err != nil {
+		t.Error(err)
+	}
+	if cols, rows := e.Size(); cols != 100 || rows != 30 {
+		t.Errorf("expected 100x30 got %dx%d", cols, rows)
+	}
+
+	if err := e.SetPrompt("$ "); err != nil {
+		t.Error(err)
+	}
+	if p := e.CurrentPrompt(); p != "$ " {
+		t.Errorf(`expected "$ " got %#v`, p)
+	}
+
+	if err := e.SetLine("foo"); err != nil {
+		t.Error(err)
+	}
+	if l := e.Line(); l != "foo" {
+		t.Errorf(`expected "foo" got %#v`, l)
+	}
+	if c := e.CursorPos(); c != 3 {
+		t.Errorf("expected 3 got %d", c)
+	}
+	if err := e.SetCursorPos(10); err != nil {
+		t.Error(err)
+	}
+	if c := e.CursorPos(); c != 3 {
+		t.Errorf("expected 3 got %d", c)
+	}
+	if err := e.SetCursorPos(-1); err != nil {
+		t.Error(err)
+	}
+	if c := e.CursorPos(); c != 0 {
+		t.Errorf("expected 0 got %d", c)
+	}
+}
+
+func TestEditor_SetPromptRepaint(t *testing.T) {
+	out := &checkedWriter{
+		expectations: []string{
+			"\r\x1b[0K\r$ ab\x1b[0K\r\x1b[4C",
+		},
+	}
+
+	e := &Terminal{
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+		Buffer: []rune("ab"),
+		Cur:    2,
+	}
+
+	// nothing is drawn outside of LineEditor
+	if err := e.SetPrompt("# "); err != nil {
+		t.Error(err)
+	}
+
+	e.active = true
+	if err := e.SetPrompt("$ "); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEditor_SetPromptInvalid(t *testing.T) {
+	e := &Terminal{Prompt: "> "}
+	for _, p := range []string{"a\bb> ", "\t> ", "\xff> "} {
+		if err := e.SetPrompt(p); err == nil {
+			t.Errorf("%q: err expected", p)
+		}
+	}
+	if e.Prompt != "> " {
+		t.Errorf(`expected "> " got %q`, e.Prompt)
+	}
+	for _, p := range []string{"\x1b[1m> \x1b[0m", "~/src\n> ", "\x1b]0;title\a> "} {
+		if err := e.SetPrompt(p); err != nil {
+			t.Errorf("%q: %v", p, err)
+		}
+	}
+}
+
+func TestEditor_SettersConcurrent(t *testing.T) {
+	pr, pw := io.Pipe()
+	e := &Terminal{
+		Inp:    bufio.NewReader(pr),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 50 {
+			e.SetPrompt(fmt.Sprintf("%d> ", i))
+			e.SetLine("foo")
+			e.SetCursorPos(i)
+		}
+	}()
+	go func() {
+		for range 50 {
+			pw.Write([]byte("a\x02"))
+		}
+		<-done
+		pw.Write([]byte("\x05\x0d"))
+	}()
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the keys typed after the last SetLine go anywhere into "foo"
+	if strings.ReplaceAll(l, "a", "") != "foo" {
+		t.Errorf(`expected "foo" and typed text got %q`, l)
+	}
+	if e.Prompt != "49> " {
+		t.Errorf(`expected "49> " got %q`, e.Prompt)
+	}
+}
+
+func TestEditor_GettersConcurrent(t *testing.T) {
+	e := &Terminal{Out: bufio.NewWriter(&bytes.Buffer{}), Prompt: "> "}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 50 {
+			e.SetSize(80+i, 24)
+			e.SetLine(strings.Repeat("a", i))
+			e.SetPrompt(fmt.Sprintf("%d> ", i))
+		}
+	}()
+	for range 50 {
+		e.Size()
+		e.Line()
+		e.CursorPos()
+		e.CurrentPrompt()
+	}
+	<-done
+
+	if cols, rows := e.Size(); cols != 129 || rows != 24 {
+		t.Errorf("expected 129x24 got %dx%d", cols, rows)
+	}
+}
+
+func TestEditor_SetSizeWhileReading(t *testing.T) {
+	pr, pw := io.Pipe()
+	c := telnet.Wrap(&rwc{Reader: pr})
+	e := NewTerminal(c, "> ")
+	c.OnResize = func(cols, rows int) { e.SetSize(cols, rows) }
+
+	go func() {
+		// the window size report comes while Ctrl-V waits for the key to insert
+		pw.Write([]byte("\x16"))
+		pw.Write([]byte("\xff\xfa\x1f\x00\x64\x00\x1e\xff\xf0"))
+		pw.Write([]byte("a\r"))
+	}()
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "a" {
+		t.Errorf(`expected "a" got %q`, l)
+	}
+	waitFor(t, "the size", func() bool {
+		cols, rows := e.Size()
+		return cols == 100 && rows == 30
+	})
+}
@@ -0,0 +1,46 @@
+package linenoisy
+
+// EnterAltScreen clears the edit region and switches to the alternate screen buffer of the terminal,
+// where the application can run a full-screen view. The line being edited is kept
+// and rendering is suspended until ExitAltScreen. Like Suspend, it is safe to call from other goroutines.
+func (e *Terminal) EnterAltScreen() error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	return e.enterAltScreen()
+}
+
+func (e *Terminal) enterAltScreen() error {
+	if e.altScreen {
+		return nil
+	}
+	e.altSuspended = e.suspended
+	if err := e.suspend(); err != nil {
+		return err
+	}
+	e.altScreen = true
+	return e.writeSeq("\x1b[?1049h")
+}
+
+// ExitAltScreen switches back to the main screen, where the cursor returns to the start of the edit region,
+// and redraws the prompt and the line unless rendering had been suspended before EnterAltScreen.
+func (e *Terminal) ExitAltScreen() error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	return e.exitAltScreen()
+}
+
+func (e *Terminal) exitAltScreen() error {
+	if !e.altScreen {
+		return nil
+	}
+	e.altScreen = false
+	if err := e.writeSeq("\x1b[?1049l"); err != nil {
+		return err
+	}
+	if e.altSuspended {
+		return nil
+	}
+	return e.resume()
+}
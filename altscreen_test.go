@@ -0,0 +1,57 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestEditor_AltScreen(t *testing.T) {
+	var out bytes.Buffer
+	e := &Terminal{
+		Out:    bufio.NewWriter(&out),
+		Prompt: "> ",
+		Buffer: []rune("foo"),
+		Cur:    1,
+	}
+
+	if err := e.EnterAltScreen(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.EnterAltScreen(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\r\x1b[0K\x1b[?1049h"; out.String() != want {
+		t.Errorf("expected %q got %q", want, out.String())
+	}
+
+	out.Reset()
+	if err := e.ExitAltScreen(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b[?1049l\r> foo\x1b[0K\r\x1b[3C"; out.String() != want {
+		t.Errorf("expected %q got %q", want, out.String())
+	}
+}
+
+func TestEditor_AltScreenSuspended(t *testing.T) {
+	var out bytes.Buffer
+	e := &Terminal{Out: bufio.NewWriter(&out), Prompt: "> "}
+
+	if err := e.Suspend(); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := e.EnterAltScreen(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.ExitAltScreen(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b[?1049h\x1b[?1049l"; out.String() != want {
+		t.Errorf("expected %q got %q", want, out.String())
+	}
+	if !e.suspended {
+		t.Error("expected rendering to stay suspended")
+	}
+}
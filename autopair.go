@@ -0,0 +1,58 @@
+package linenoisy
+
+import (
+	"slices"
+	"unicode"
+)
+
+// DefaultAutoPairs are brackets and quotes for AutoPairs.
+var DefaultAutoPairs = map[rune]rune{'(': ')', '[': ']', '{': '}', '"': '"', '\'': '\''}
+
+// autoPair types r with AutoPairs and reports whether it did: a closing rune steps over the same rune
+// at the cursor, an opening rune is inserted with its closing one and the cursor between them.
+// A quote right after a letter or digit, like the apostrophe of "don't", is not paired.
+func (e *Terminal) autoPair(r rune) (bool, error) {
+	if e.AutoPairs == nil || e.Overwrite {
+		return false, nil
+	}
+
+	if e.Cur < len(e.Buffer) && e.Buffer[e.Cur] == r && slices.Contains(closers(e.AutoPairs), r) {
+		e.Cur++
+		return true, e.refreshLine()
+	}
+
+	c, ok := e.AutoPairs[r]
+	if !ok {
+		return false, nil
+	}
+	if c == r && e.Cur > 0 && (unicode.IsLetter(e.Buffer[e.Cur-1]) || unicode.IsDigit(e.Buffer[e.Cur-1])) {
+		return false, nil
+	}
+	if e.room(2) < 2 {
+		return false, nil
+	}
+	e.Buffer = slices.Insert(e.Buffer, e.Cur, r, c)
+	e.Cur++
+	return true, e.refreshLine()
+}
+
+// autoUnpair deletes an empty pair around the cursor on Backspace and reports whether it did.
+func (e *Terminal) autoUnpair() (bool, error) {
+	if e.AutoPairs == nil || e.Cur == 0 || e.Cur == len(e.Buffer) {
+		return false, nil
+	}
+	if c, ok := e.AutoPairs[e.Buffer[e.Cur-1]]; !ok || c != e.Buffer[e.Cur] {
+		return false, nil
+	}
+	e.Buffer = slices.Delete(e.Buffer, e.Cur-1, e.Cur+1)
+	e.Cur--
+	return true, e.refreshLine()
+}
+
+func closers(pairs map[rune]rune) []rune {
+	var cs []rune
+	for _, c := range pairs {
+		cs = append(cs, c)
+	}
+	return cs
+}
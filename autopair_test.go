@@ -0,0 +1,36 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestEditor_AutoPairs(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		line string
+	}{
+		{"(f x\x0d", "(f x)"},
+		{"(f x)\x0d", "(f x)"},
+		{"(f [a]) y\x0d", "(f [a]) y"},
+		{"echo \"hi\x0d", "echo \"hi\""},
+		{"don't\x0d", "don't"},
+		{"(\x7fx\x0d", "x"},
+		{"(a\x7f\x7f\x0d", ""},
+	} {
+		e := &Terminal{
+			Inp:       bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:       bufio.NewWriter(&bytes.Buffer{}),
+			Prompt:    "> ",
+			AutoPairs: DefaultAutoPairs,
+		}
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+		}
+		if l != tt.line {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.line, l)
+		}
+	}
+}
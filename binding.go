@@ -0,0 +1,68 @@
+package linenoisy
+
+import "fmt"
+
+// editFuncs are the editing functions keys can be bound to, named after their GNU readline counterparts.
+var editFuncs = map[string]func(e *Terminal) error{
+	"backward-char":             (*Terminal).editMoveLeft,
+	"forward-char":              (*Terminal).editMoveRight,
+	"backward-word":             (*Terminal).editWordLeft,
+	"forward-word":              (*Terminal).editWordRight,
+	"character-search":          func(e *Terminal) error { return e.editCharSearch(+1) },
+	"character-search-backward": func(e *Terminal) error { return e.editCharSearch(-1) },
+	"beginning-of-line":         (*Terminal).editMoveHome,
+	"end-of-line":               (*Terminal).editMoveEnd,
+	"backward-delete-char":      (*Terminal).editBackspace,
+	"delete-char":               (*Terminal).editDelete,
+	"transpose-chars":           (*Terminal).editSwap,
+	"previous-history":          (*Terminal).editHistoryPrev,
+	"next-history":              (*Terminal).editHistoryNext,
+	"beginning-of-history":      (*Terminal).editHistoryFirst,
+	"end-of-history":            (*Terminal).editHistoryLast,
+	"browse-history":            (*Terminal).editBrowseHistory,
+	"history-search-backward":   func(e *Terminal) error { return e.editHistorySearch(-1) },
+	"history-search-forward":    func(e *Terminal) error { return e.editHistorySearch(+1) },
+	"kill-line":                 (*Terminal).editKillForward,
+	"unix-line-discard":         (*Terminal).editKillBackward,
+	"kill-whole-line":           (*Terminal).editKillWholeLine,
+	"unix-word-rubout":          (*Terminal).editDeletePrevWord,
+	"kill-word":                 (*Terminal).editDeleteNextWord,
+	"backward-kill-word":        (*Terminal).editBackwardKillWord,
+	"yank":                      (*Terminal).editYank,
+	"yank-last-arg":             (*Terminal).editYankLastArg,
+	"set-mark":                  (*Terminal).editSetMark,
+	"kill-region":               (*Terminal).editKillRegion,
+	"copy-region-as-kill":       (*Terminal).editCopyRegion,
+	"complete":                  (*Terminal).completeLine,
+	"help":                      (*Terminal).printHelp,
+	"quoted-insert":             (*Terminal).editQuotedInsert,
+	"copy-line-to-clipboard":    (*Terminal).editCopyLine,
+	"paste-from-clipboard":      (*Terminal).editPasteClipboard,
+	"abort":                     (*Terminal).editAbort,
+	"clear-screen": func(e *Terminal) error {
+		if err := e.clearScreen(); err != nil {
+			return err
+		}
+		return e.refreshLine()
+	},
+}
+
+// Bind makes k run the named editing function instead of its default one.
+// Names follow GNU readline, e.g. Bind(Key{Rune: 'u', Mod: ModCtrl}, "kill-whole-line") brings back
+// the Ctrl-U which cleared the whole line.
+func (e *Terminal) Bind(k Key, name string) error {
+	f, ok := editFuncs[name]
+	if !ok {
+		return fmt.Errorf("unknown editing function %q", name)
+	}
+	if e.bindings == nil {
+		e.bindings = map[Key]func(*Terminal) error{}
+	}
+	e.bindings[k] = f
+	return nil
+}
+
+// Unbind restores the default function of k.
+func (e *Terminal) Unbind(k Key) {
+	delete(e.bindings, k)
+}
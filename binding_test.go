@@ -0,0 +1,48 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestEditor_Bind(t *testing.T) {
+	tests := []struct {
+		name string
+		bind map[Key]string
+		in   string
+		line string
+	}{
+		{"ctrl-u kills to line start", nil, "foo bar\x02\x02\x15\x0d", "ar"},
+		{"yank", nil, "foo bar\x02\x02\x15\x05 \x19\x0d", "ar foo b"},
+		{"ctrl-k then yank", nil, "foo bar\x01\x0b\x19\x19\x0d", "foo barfoo bar"},
+		{"ctrl-w keeps the tail", nil, "foo bar\x02\x17\x0d", "foo r"},
+		{"kill-whole-line", map[Key]string{ctrl('u'): "kill-whole-line"}, "foo bar\x02\x02\x15x\x19\x0d", "xfoo bar"},
+		{"rebound key", map[Key]string{ctrl('g'): "beginning-of-line"}, "bar\x07foo \x0d", "foo bar"},
+	}
+	for _, tt := range tests {
+		e := &Terminal{
+			Inp:    bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:    bufio.NewWriter(&bytes.Buffer{}),
+			Prompt: "> ",
+		}
+		for k, name := range tt.bind {
+			if err := e.Bind(k, name); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if l != tt.line {
+			t.Errorf("%s: expected %q got %q", tt.name, tt.line, l)
+		}
+	}
+
+	e := &Terminal{}
+	if err := e.Bind(ctrl('x'), "no-such-function"); err == nil {
+		t.Error("err expected")
+	}
+}
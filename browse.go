@@ -0,0 +1,136 @@
+package linenoisy
+
+import "slices"
+
+// editBrowseHistory lists the history on the alternate screen, the newest entries first, filtered and ranked
+// by a fuzzy match of the typed query (see FuzzyFilter). Up and Down or Ctrl-P and Ctrl-N move the selection,
+// PageUp and PageDown by a screen, Backspace shortens the query and Ctrl-U clears it. Enter puts the selected
+// entry into the line, Escape, Ctrl-G or Ctrl-C leave the line as it was.
+func (e *Terminal) editBrowseHistory() error {
+	ms, err := e.History.Search("", SearchOptions{})
+	if err != nil {
+		return err
+	}
+	var lines []string
+	notes := map[string]string{}
+	for _, m := range ms {
+		if _, ok := notes[m.Line]; !ok {
+			notes[m.Line] = e.History.Annotation(m.Index)
+			lines = append(lines, m.Line)
+		}
+	}
+	if len(lines) == 0 {
+		return e.beep()
+	}
+
+	if err := e.enterAltScreen(); err != nil {
+		return err
+	}
+	e.notZero()
+	if err := e.writeSeq(e.caps().ClearScreen); err != nil {
+		e.exitAltScreen()
+		return err
+	}
+
+	var query []rune
+	found := FuzzyFilter("", lines)
+	sel, top := 0, 0
+	for {
+		page := e.Rows - 1
+		sel = min(max(sel, 0), max(len(found)-1, 0))
+		top = min(max(top, sel-page+1), sel)
+		if err := e.drawBrowser(query, found, notes, sel, top); err != nil {
+			e.exitAltScreen()
+			return err
+		}
+
+		k, err := e.readKey()
+		if err != nil {
+			e.exitAltScreen()
+			return err
+		}
+		switch {
+		case k == Key{Code: KeyEnter}:
+			if len(found) == 0 {
+				continue
+			}
+			e.History.Save(string(e.Buffer))
+			e.historyUsed = e.History.seek(found[sel].Text)
+			e.Buffer = []rune(found[sel].Text)
+			e.Cur = len(e.Buffer)
+			return e.exitAltScreen()
+		case k == Key{Code: KeyEscape}, k == ctrl('g'), k == ctrl('c'):
+			return e.exitAltScreen()
+		case k == Key{Code: KeyUp}, k == ctrl('p'):
+			sel--
+		case k == Key{Code: KeyDown}, k == ctrl('n'):
+			sel++
+		case k == Key{Code: KeyPageUp}:
+			sel -= page
+		case k == Key{Code: KeyPageDown}:
+			sel += page
+		case k == Key{Code: KeyBackspace}, k == ctrl('h'):
+			if len(query) == 0 {
+				continue
+			}
+			query = query[:len(query)-1]
+			found, sel, top = FuzzyFilter(string(query), lines), 0, 0
+		case k == ctrl('u'):
+			query = nil
+			found, sel, top = FuzzyFilter("", lines), 0, 0
+		case k.Code == KeyRune && k.Mod == 0:
+			query = append(query, k.Rune)
+			found, sel, top = FuzzyFilter(string(query), lines), 0, 0
+		}
+	}
+}
+
+// drawBrowser writes the query on the first row of the screen and the matches from top below it, the selected
+// one marked with '>' and each followed by its annotation in HintStyle, if there is room. The cursor starts and ends
+// on the first row.
+func (e *Terminal) drawBrowser(query []rune, found []FuzzyMatch, notes map[string]string, sel, top int) error {
+	caps := e.caps()
+	ew := &errWriter{w: e.Out}
+	ew.writeString("\r> " + string(query) + caps.ClearEOL)
+	n := 0
+	for i := top; i < len(found) && i < top+e.Rows-1; i++ {
+		mark := "  "
+		if i == sel {
+			mark = "> "
+		}
+		// the last column stays free, erasing from there would take the last character away
+		m := e.fitMatch(found[i], e.Cols-3)
+		text := m.Text
+		if !e.plain() {
+			text = fuzzyHighlight(m)
+		}
+		ew.writeString("\r\n" + mark + text)
+		if room := e.Cols - 4 - e.width(m.Text); notes[found[i].Text] != "" && room > 0 {
+			ew.writeString(" " + e.styleHint(e.truncate(notes[found[i].Text], room)))
+		}
+		ew.writeString(caps.ClearEOL)
+		n++
+	}
+	ew.writeString(caps.ClearEOS)
+	if n > 0 {
+		ew.writeString(caps.up(n))
+	}
+	ew.writeString("\r" + caps.right(2+e.runesWidth(query)))
+	ew.flush()
+	return ew.err
+}
+
+// fitMatch cuts the text of m to w columns.
+func (e *Terminal) fitMatch(m FuzzyMatch, w int) FuzzyMatch {
+	rs := []rune(m.Text)
+	n := len(rs)
+	for n > 0 && e.runesWidth(rs[:n]) > w {
+		n--
+	}
+	if n == len(rs) {
+		return m
+	}
+	m.Text = string(rs[:n])
+	m.Positions = slices.DeleteFunc(slices.Clone(m.Positions), func(p int) bool { return p >= n })
+	return m
+}
@@ -0,0 +1,127 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Joker/linenoisy/vtest"
+)
+
+func TestEditor_LineBrowseHistory(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"x\x18\x12gpl\r\r", "git pull"},
+		{"x\x18\x12\x1b[B\r\r", "ls"},
+		{"x\x18\x12\x1b[A\x1b[A\r\r", "git push"},
+		{"x\x18\x12gp\x7f\x7fl\r\r", "ls"},
+		{"x\x18\x12zzz\r\x07\r", "x"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		e := &Terminal{
+			Inp:    bufio.NewReader(strings.NewReader(tt.in)),
+			Out:    bufio.NewWriter(&out),
+			Prompt: "> ",
+		}
+		e.History.Add("git pull")
+		e.History.Add("ls")
+		e.History.Add("git push")
+
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l != tt.want {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.want, l)
+		}
+		if !strings.Contains(out.String(), "\x1b[?1049h") || !strings.Contains(out.String(), "\x1b[?1049l") {
+			t.Errorf("%q: expected the alternate screen in %q", tt.in, out.String())
+		}
+	}
+}
+
+func TestEditor_LineBrowseHistoryShared(t *testing.T) {
+	shared := &History{}
+	other := History{Shared: shared}
+	e := &Terminal{
+		Inp:     bufio.NewReader(strings.NewReader("\x18\x12ma\r\x1b[A\r")),
+		Out:     bufio.NewWriter(&bytes.Buffer{}),
+		Prompt:  "> ",
+		History: History{Shared: shared},
+	}
+	e.History.Add("ls")
+	e.History.Add("pwd")
+	other.Add("make") // not seen by e yet
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Up goes on from the picked entry of the shared history
+	if l != "pwd" {
+		t.Errorf(`expected "pwd" got %q`, l)
+	}
+}
+
+func TestEditor_drawBrowser(t *testing.T) {
+	s := vtest.New(20, 4)
+	e := &Terminal{Out: bufio.NewWriter(s), Rows: 4, Cols: 20}
+	notes := map[string]string{"git push --force": "exit 1", "gpg --list-keys": "exit 0"}
+
+	s.Write([]byte("stale\r\nstale\r\nstale\r\nstale\x1b[H"))
+	found := FuzzyFilter("gp", []string{"git push --force", "ls", "gpg --list-keys"})
+	if err := e.drawBrowser([]rune("gp"), found, notes, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"> gp",
+		"  gpg --list-keys …", // the note cut to the room left
+		"> git push --force",
+		"",
+	}
+	if got := s.Lines(); !slices.Equal(got, want) {
+		t.Errorf("expected %q got %q", want, got)
+	}
+	if col, row := s.Cursor(); col != 4 || row != 0 {
+		t.Errorf("expected cursor 4,0 got %d,%d", col, row)
+	}
+
+	s = vtest.New(40, 4)
+	e = &Terminal{Out: bufio.NewWriter(s), Rows: 4, Cols: 40}
+	if err := e.drawBrowser(nil, FuzzyFilter("", []string{"git push --force"}), notes, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if want := "> git push --force exit 1"; s.Line(1) != want {
+		t.Errorf("expected %q got %q", want, s.Line(1))
+	}
+}
+
+func TestEditor_drawBrowserCaps(t *testing.T) {
+	for _, tt := range []struct {
+		noStyle bool
+		hint    []byte
+		want    string
+	}{
+		{false, nil, "\r\n> \x1b[1ml\x1b[0ms \x1b[2mexit 0\x1b[0m<el>"},
+		{false, []byte{}, "\r\n> \x1b[1ml\x1b[0ms exit 0<el>"},
+		{true, nil, "\r\n> ls exit 0<el>"}, // no SGR on a terminal without attributes
+	} {
+		var out bytes.Buffer
+		e := &Terminal{Out: bufio.NewWriter(&out), Rows: 3, Cols: 20, HintStyle: tt.hint, Caps: &Capabilities{
+			ClearEOL: "<el>", ClearEOS: "<ed>", Up: "<up>", Right: "<right>", NoStyle: tt.noStyle,
+		}}
+
+		found := FuzzyFilter("l", []string{"ls"})
+		if err := e.drawBrowser([]rune("l"), found, map[string]string{"ls": "exit 0"}, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+		want := "\r> l<el>" + tt.want + "<ed><up>\r<right><right><right>"
+		if out.String() != want {
+			t.Errorf("expected %q got %q", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package linenoisy
+
+import "unicode/utf8"
+
+// bulkRun is the length of a run of buffered printable runes from which they are inserted at once.
+// Typing ahead doesn't get that far, pasting does.
+const bulkRun = 16
+
+// readRun returns k followed by the printable runes already buffered after it, like the rest of a paste
+// without bracketed paste, as long as every one of them would just be inserted. Runs shorter than bulkRun
+// are left to be handled key by key and nil is returned.
+func (e *Terminal) readRun(k Key) []rune {
+	if k.Code != KeyRune || k.Mod != 0 || e.OnKey != nil || e.Overwrite || e.marked || len(e.AutoPairs) > 0 ||
+		!e.plainRune(k.Rune) {
+		return nil
+	}
+
+	if e.buffered() == 0 {
+		return nil
+	}
+	b, _ := e.Inp.Peek(e.buffered())
+	run, n := []rune{k.Rune}, 0
+	for utf8.FullRune(b[n:]) {
+		r, size := utf8.DecodeRune(b[n:])
+		if !e.plainRune(r) {
+			break
+		}
+		run = append(run, r)
+		n += size
+	}
+	if len(run) < bulkRun {
+		return nil
+	}
+	e.Inp.Discard(n)
+	return run
+}
+
+// plainRune reports whether typing r only inserts it.
+func (e *Terminal) plainRune(r rune) bool {
+	switch {
+	case r < ' ', r == backspace, r == utf8.RuneError:
+		return false
+	case r == ' ' && len(e.Abbrevs) > 0:
+		return false
+	}
+	_, bound := e.bindings[Key{Rune: r}]
+	return !bound
+}
+
+// insertRun inserts the runes of readRun with a single refresh instead of one per rune.
+func (e *Terminal) insertRun(run []rune) error {
+	e.keyCount += len(run)
+	e.expanded = nil
+	if err := e.cancelCompletion(); err != nil {
+		return err
+	}
+	return e.editInsertRunes(run)
+}
@@ -0,0 +1,50 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEditor_BulkInsert(t *testing.T) {
+	paste := strings.Repeat("echo hello ", 200)
+	e := &Terminal{
+		Inp:     bufio.NewReader(strings.NewReader(paste + "\r")),
+		Out:     bufio.NewWriter(&bytes.Buffer{}),
+		Prompt:  "> ",
+		Metrics: &Metrics{},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != paste {
+		t.Errorf("expected the paste got %q", l)
+	}
+	if m := e.Metrics.Snapshot(); m.Refreshes != 2 || m.Keys != int64(len(paste)+1) {
+		t.Errorf("expected 2 refreshes for %d keys got %+v", len(paste)+1, m)
+	}
+}
+
+func TestEditor_BulkInsertStops(t *testing.T) {
+	e := &Terminal{
+		Inp:     bufio.NewReader(strings.NewReader("abcdefghijklmnopqrst\x02uv\r")),
+		Out:     bufio.NewWriter(&bytes.Buffer{}),
+		Prompt:  "> ",
+		Metrics: &Metrics{},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "abcdefghijklmnopqrsuvt" {
+		t.Errorf(`expected "abcdefghijklmnopqrsuvt" got %q`, l)
+	}
+	// the empty line, the run, Ctrl-B, u and v: the run is too short after Ctrl-B
+	if m := e.Metrics.Snapshot(); m.Refreshes != 5 {
+		t.Errorf("expected 5 refreshes got %d", m.Refreshes)
+	}
+}
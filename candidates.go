@@ -0,0 +1,50 @@
+package linenoisy
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// CompleteOrder selects how the candidates of a completion are sorted.
+type CompleteOrder int
+
+const (
+	CompleteAsReturned CompleteOrder = iota // the order of the completer.
+	CompleteLexical                         // by Text.
+	CompleteByScore                         // by Score, the highest first, equal scores by Text.
+)
+
+// normalizeCandidates drops the duplicates and unchanged candidates and sorts cs as configured.
+func (e *Terminal) normalizeCandidates(cs []Candidate) []Candidate {
+	if e.CompleteHideCurrent {
+		cs = slices.DeleteFunc(cs, func(c Candidate) bool {
+			return c.Text == string(e.Buffer[c.ReplaceFrom:c.ReplaceTo])
+		})
+	}
+	if e.CompleteDedup {
+		type key struct {
+			text     string
+			from, to int
+		}
+		seen := map[key]bool{}
+		cs = slices.DeleteFunc(cs, func(c Candidate) bool {
+			k := key{c.Text, c.ReplaceFrom, c.ReplaceTo}
+			if seen[k] {
+				return true
+			}
+			seen[k] = true
+			return false
+		})
+	}
+
+	switch e.CompleteOrder {
+	case CompleteLexical:
+		slices.SortStableFunc(cs, func(a, b Candidate) int { return strings.Compare(a.Text, b.Text) })
+	case CompleteByScore:
+		slices.SortStableFunc(cs, func(a, b Candidate) int {
+			return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.Text, b.Text))
+		})
+	}
+	return cs
+}
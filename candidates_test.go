@@ -0,0 +1,36 @@
+package linenoisy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEditor_normalizeCandidates(t *testing.T) {
+	cs := []Candidate{
+		{Text: "ls", ReplaceTo: 2, Score: 1},
+		{Text: "lsof", ReplaceTo: 2, Score: 5},
+		{Text: "lsblk", ReplaceTo: 2, Score: 5},
+		{Text: "lsof", ReplaceTo: 2},
+		{Text: "less", ReplaceTo: 2, Score: 3},
+	}
+	tests := []struct {
+		e    *Terminal
+		want []string
+	}{
+		{&Terminal{}, []string{"ls", "lsof", "lsblk", "lsof", "less"}},
+		{&Terminal{CompleteDedup: true}, []string{"ls", "lsof", "lsblk", "less"}},
+		{&Terminal{CompleteHideCurrent: true}, []string{"lsof", "lsblk", "lsof", "less"}},
+		{&Terminal{CompleteOrder: CompleteLexical}, []string{"less", "ls", "lsblk", "lsof", "lsof"}},
+		{&Terminal{CompleteOrder: CompleteByScore, CompleteDedup: true}, []string{"lsblk", "lsof", "less", "ls"}},
+	}
+	for _, tt := range tests {
+		tt.e.Buffer = []rune("ls")
+		var got []string
+		for _, c := range tt.e.normalizeCandidates(slices.Clone(cs)) {
+			got = append(got, c.Text)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("expected %q got %q", tt.want, got)
+		}
+	}
+}
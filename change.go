@@ -0,0 +1,28 @@
+package linenoisy
+
+import "slices"
+
+// editState is Buffer and Cur at some point, for OnChange.
+type editState struct {
+	line []rune
+	cur  int
+}
+
+// snapshot returns the current Buffer and Cur if OnChange wants to know about their changes.
+func (e *Terminal) snapshot() *editState {
+	if e.OnChange == nil {
+		return nil
+	}
+	return &editState{line: slices.Clone(e.Buffer), cur: e.Cur}
+}
+
+// notifyChange calls OnChange if Buffer or Cur differ from before.
+func (e *Terminal) notifyChange(before *editState) {
+	if before == nil || e.OnChange == nil {
+		return
+	}
+	if e.Cur == before.cur && slices.Equal(e.Buffer, before.line) {
+		return
+	}
+	e.OnChange(string(e.Buffer), e.Cur)
+}
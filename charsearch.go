@@ -0,0 +1,21 @@
+package linenoisy
+
+// editCharSearch reads a character and moves the cursor to its next occurrence after the cursor for dir > 0,
+// or to its previous one before the cursor for dir < 0, like Ctrl-] and Alt-Ctrl-] of readline.
+func (e *Terminal) editCharSearch(dir int) error {
+	k, err := e.readKey()
+	if err != nil {
+		return err
+	}
+	if k.Code != KeyRune || k.Mod != 0 {
+		return e.beep()
+	}
+
+	for i := e.Cur + dir; i >= 0 && i < len(e.Buffer); i += dir {
+		if e.Buffer[i] == k.Rune {
+			e.Cur = i
+			return e.refreshLine()
+		}
+	}
+	return e.beep()
+}
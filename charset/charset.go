@@ -0,0 +1,123 @@
+// Package charset lets clients that don't speak UTF-8 use the line editor.
+//
+//	e := linenoisy.NewTerminal(charset.Wrap(conn, charset.Latin1), "> ")
+package charset
+
+import (
+	"io"
+	"sync"
+	"unicode/utf8"
+)
+
+// Charset maps the bytes of a single byte character set to runes.
+// The charmaps of golang.org/x/text/encoding/charmap implement it.
+type Charset interface {
+	DecodeByte(b byte) rune
+	EncodeRune(r rune) (b byte, ok bool)
+}
+
+// Latin1 is ISO 8859-1, whose bytes are the first 256 code points.
+var Latin1 Charset = latin1{}
+
+type latin1 struct{}
+
+func (latin1) DecodeByte(b byte) rune { return rune(b) }
+
+func (latin1) EncodeRune(r rune) (byte, bool) {
+	if r < 0 || r > 0xff {
+		return 0, false
+	}
+	return byte(r), true
+}
+
+// Replacement is written for runes the charset can't represent.
+const Replacement = '?'
+
+// Conn decodes the input stream of a client to UTF-8 and encodes the output of the editor to its charset.
+type Conn struct {
+	rwc io.ReadWriteCloser
+	cs  Charset
+
+	rbuf []byte // input read from rwc.
+	dec  []byte // decoded input not yet returned by Read.
+	rerr error  // error of the read dec came from.
+
+	wmu     sync.Mutex
+	partial []byte // incomplete UTF-8 sequence at the end of the last Write.
+}
+
+// Wrap transcodes between the UTF-8 of the editor and cs on c.
+func Wrap(c io.ReadWriteCloser, cs Charset) *Conn {
+	return &Conn{rwc: c, cs: cs}
+}
+
+func (t *Conn) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(t.dec) == 0 {
+		if t.rerr != nil {
+			err := t.rerr
+			t.rerr = nil
+			return 0, err
+		}
+		if cap(t.rbuf) < len(p) {
+			t.rbuf = make([]byte, len(p))
+		}
+		n, err := t.rwc.Read(t.rbuf[:len(p)])
+		for _, b := range t.rbuf[:n] {
+			if b < utf8.RuneSelf {
+				t.dec = append(t.dec, b)
+				continue
+			}
+			t.dec = utf8.AppendRune(t.dec, t.cs.DecodeByte(b))
+		}
+		if len(t.dec) == 0 {
+			return 0, err
+		}
+		t.rerr = err
+	}
+	n := copy(p, t.dec)
+	t.dec = t.dec[n:]
+	return n, nil
+}
+
+// Write encodes p to the charset. A UTF-8 sequence split across calls is written once it's complete.
+func (t *Conn) Write(p []byte) (int, error) {
+	t.wmu.Lock()
+	defer t.wmu.Unlock()
+
+	in := p
+	if len(t.partial) > 0 {
+		in = append(t.partial, p...)
+		t.partial = nil
+	}
+
+	buf := make([]byte, 0, len(in))
+	for len(in) > 0 {
+		if in[0] < utf8.RuneSelf {
+			buf = append(buf, in[0])
+			in = in[1:]
+			continue
+		}
+		if !utf8.FullRune(in) {
+			t.partial = append([]byte(nil), in...)
+			break
+		}
+		r, size := utf8.DecodeRune(in)
+		in = in[size:]
+		b, ok := t.cs.EncodeRune(r)
+		if r == utf8.RuneError || !ok {
+			b = Replacement
+		}
+		buf = append(buf, b)
+	}
+	if _, err := t.rwc.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (t *Conn) Close() error {
+	return t.rwc.Close()
+}
@@ -0,0 +1,79 @@
+package charset
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestConn_Read(t *testing.T) {
+	ch := &rwc{Reader: bytes.NewReader([]byte("caf\xe9 \x1b[D\xff"))}
+	c := Wrap(ch, Latin1)
+
+	b, err := io.ReadAll(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "café \x1b[Dÿ"; got != want {
+		t.Errorf("expected %#v got %#v", want, got)
+	}
+}
+
+func TestConn_Read_short(t *testing.T) {
+	ch := &rwc{Reader: bytes.NewReader([]byte("\xe9"))}
+	c := Wrap(ch, Latin1)
+
+	var got []byte
+	p := make([]byte, 1)
+	for {
+		n, err := c.Read(p)
+		got = append(got, p[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "é"; string(got) != want {
+		t.Errorf("expected %#v got %#v", want, string(got))
+	}
+}
+
+func TestConn_Write(t *testing.T) {
+	ch := &rwc{Reader: bytes.NewReader(nil)}
+	c := Wrap(ch, Latin1)
+
+	p := []byte("café → ok")
+	n, err := c.Write(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(p) {
+		t.Errorf("expected %d got %d", len(p), n)
+	}
+	if got, want := ch.String(), "caf\xe9 ? ok"; got != want {
+		t.Errorf("expected %#v got %#v", want, got)
+	}
+
+	ch.Reset()
+	e := []byte("é")
+	if _, err := c.Write(append([]byte("x"), e[0])); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Write(e[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ch.String(), "x\xe9"; got != want {
+		t.Errorf("expected %#v got %#v", want, got)
+	}
+}
+
+type rwc struct {
+	io.Reader
+	bytes.Buffer
+}
+
+func (c *rwc) Read(p []byte) (int, error)  { return c.Reader.Read(p) }
+func (c *rwc) Write(p []byte) (int, error) { return c.Buffer.Write(p) }
+func (c *rwc) Close() error                { return nil }
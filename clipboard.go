@@ -0,0 +1,55 @@
+package linenoisy
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// setClipboard sends s to the clipboard of the terminal with OSC 52.
+func (e *Terminal) setClipboard(s string) error {
+	return e.writeSeq("\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(s)) + "\a")
+}
+
+// editCopyLine puts the whole line on the clipboard of the terminal, on Ctrl-X Ctrl-W.
+func (e *Terminal) editCopyLine() error {
+	if !e.Clipboard || len(e.Buffer) == 0 {
+		return e.beep()
+	}
+	return e.setClipboard(string(e.Buffer))
+}
+
+// editPasteClipboard asks the terminal for its clipboard on Ctrl-X Ctrl-Y. The reply arrives as KeyClipboard,
+// terminals which don't allow reading the clipboard ignore the request.
+func (e *Terminal) editPasteClipboard() error {
+	if !e.Clipboard {
+		return e.beep()
+	}
+	return e.writeSeq("\x1b]52;c;?\a")
+}
+
+// insertClipboard inserts the clipboard content of a KeyClipboard reply, line breaks become spaces.
+func (e *Terminal) insertClipboard() error {
+	s := strings.TrimRight(e.clipboard, "\r\n")
+	e.clipboard = ""
+	s = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+	s = sanitizePaste(s)
+	if s == "" {
+		return e.beep()
+	}
+	return e.editInsertRunes([]rune(s))
+}
+
+// decodeOSC decodes an OSC sequence from the terminal, only the reply to a clipboard request is a key.
+func (e *Terminal) decodeOSC(seq string) Key {
+	data, ok := strings.CutPrefix(seq, "52;")
+	if !ok {
+		return Key{Code: KeyUnknown}
+	}
+	_, data, _ = strings.Cut(data, ";")
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return Key{Code: KeyUnknown}
+	}
+	e.clipboard = string(b)
+	return Key{Code: KeyClipboard}
+}
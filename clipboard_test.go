@@ -0,0 +1,50 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEditor_LineClipboard(t *testing.T) {
+	in := bytes.NewBufferString("foo bar\x17\x18\x17\x18\x19\x1b]52;c;eCB5Cno=\a\r")
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp:       bufio.NewReader(in),
+		Out:       bufio.NewWriter(&out),
+		Prompt:    "> ",
+		Clipboard: true,
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "foo x y z" {
+		t.Errorf(`expected "foo x y z" got %#v`, l)
+	}
+	for _, seq := range []string{
+		"\x1b]52;c;YmFy\a",     // killed "bar"
+		"\x1b]52;c;Zm9vIA==\a", // the line "foo "
+		"\x1b]52;c;?\a",
+	} {
+		if !strings.Contains(out.String(), seq) {
+			t.Errorf("expected %q in %q", seq, out.String())
+		}
+	}
+}
+
+func TestEditor_readKeyClipboard(t *testing.T) {
+	e := &Terminal{Inp: bufio.NewReader(strings.NewReader("\x1b]52;c;aGk=\x1b\\\x1b]11;rgb:0/0/0\a"))}
+	k, err := e.readKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k != (Key{Code: KeyClipboard}) || e.clipboard != "hi" {
+		t.Errorf("expected Clipboard with \"hi\" got %v with %#v", k, e.clipboard)
+	}
+	if k, err := e.readKey(); err != nil || k != (Key{Code: KeyUnknown}) {
+		t.Errorf("expected Unknown got %v, %v", k, err)
+	}
+}
@@ -0,0 +1,53 @@
+package linenoisy
+
+// Close leaves the terminal usable for whatever follows the application: the line being edited is drawn
+// once more without hint and highlighting, the cursor moves below it, the modes LineEditor enabled and
+// the alternate screen and status bar are switched off, Out is flushed and Raw closed. A running LineEditor
+// then returns the read error of the closed Raw. Like WriteOut, it is safe to call from other goroutines.
+func (e *Terminal) Close() error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	ew := &errWriter{w: e.Out}
+	if e.completion != nil {
+		e.completion.cancel()
+		e.completion = nil
+	}
+	if e.hinting != nil {
+		e.hinting.cancel()
+		e.hinting = nil
+	}
+	if e.altScreen {
+		ew.writeString("\x1b[?1049l")
+		e.altScreen = false
+		e.suspended = e.altSuspended
+	}
+	if e.active && !e.suspended {
+		e.clearRegion(ew)
+		e.clearStatus(ew)
+		e.writePrompt(ew)
+		ew.writeString(string(e.Buffer))
+		ew.writeString("\r\n")
+	}
+	e.removeStatusBar(ew)
+	if e.active && e.bracketedPaste() {
+		ew.writeString("\x1b[?2004l")
+	}
+	if e.active && e.mouse() {
+		ew.writeString("\x1b[?1006l\x1b[?1000l")
+	}
+	if e.active && e.kittyKeyboard() {
+		ew.writeString("\x1b[<u")
+	}
+	e.active = false
+	e.suspended = true // nothing is drawn anymore
+	ew.flush()
+
+	if e.Raw == nil {
+		return ew.err
+	}
+	if err := e.Raw.Close(); err != nil && ew.err == nil {
+		return err
+	}
+	return ew.err
+}
@@ -0,0 +1,57 @@
+package linenoisy
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+type closeRecorder struct {
+	strings.Builder
+	closed bool
+}
+
+func (c *closeRecorder) Read(p []byte) (int, error) { return 0, io.EOF }
+func (c *closeRecorder) Close() error               { c.closed = true; return nil }
+
+func TestEditor_Close(t *testing.T) {
+	r, w := io.Pipe()
+	ch := &closeRecorder{}
+	out := &lockedBuffer{}
+	e := &Terminal{
+		Inp:            bufio.NewReader(r),
+		Out:            bufio.NewWriter(out),
+		Raw:            ch,
+		Prompt:         "> ",
+		BracketedPaste: true,
+		Hint:           func(line string) string { return " <hint>" },
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := e.LineEditor()
+		done <- err
+	}()
+	w.Write([]byte("ls"))
+	waitFor(t, "the line", func() bool { return strings.Contains(out.String(), "> ls\x1b[2m <hint>") })
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !ch.closed {
+		t.Error("expected Raw closed")
+	}
+	want := "\r\x1b[0K> ls\r\n\x1b[?2004l"
+	if !strings.HasSuffix(out.String(), want) {
+		t.Errorf("expected %q at the end of %q", want, out.String())
+	}
+
+	w.CloseWithError(io.ErrClosedPipe)
+	if err := <-done; err != io.ErrClosedPipe {
+		t.Errorf("expected %v got %v", io.ErrClosedPipe, err)
+	}
+	if !strings.HasSuffix(out.String(), want) {
+		t.Errorf("expected nothing after Close got %q", out.String())
+	}
+}
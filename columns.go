@@ -0,0 +1,59 @@
+package linenoisy
+
+import "strings"
+
+// writeColumns writes every row on its own line after indent, left-aligning the cells in columns
+// padded by padding spaces. Cell widths come from WidthChar, so wide characters don't break the alignment.
+func (e *Terminal) writeColumns(ew *errWriter, indent string, rows [][]string, padding int) {
+	colw := e.columnWidths(rows)
+	for _, row := range rows {
+		e.writeRow(ew, indent, row, colw, padding)
+	}
+	ew.writeString("\n")
+}
+
+func (e *Terminal) columnWidths(rows [][]string) []int {
+	var colw []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(colw) {
+				colw = append(colw, 0)
+			}
+			colw[i] = max(colw[i], e.width(cell))
+		}
+	}
+	return colw
+}
+
+func (e *Terminal) writeRow(ew *errWriter, indent string, row []string, colw []int, padding int) {
+	e.leaveRegion()
+	ew.writeString("\n\r")
+	ew.writeString(indent)
+	for i, cell := range row {
+		ew.writeString(cell)
+		ew.writeString(strings.Repeat(" ", colw[i]-e.width(cell)+padding))
+	}
+}
+
+// layoutColumns arranges cells top to bottom, then left to right, in as many columns as the widest cell
+// allows on a row of Cols after indent columns; with a single column, cells wider than the row are truncated.
+func (e *Terminal) layoutColumns(cells []string, indent, padding int) [][]string {
+	e.notZero()
+	avail := e.Cols - indent - 1 // the last column would wrap the row
+
+	widest := 0
+	for _, c := range cells {
+		widest = max(widest, e.width(c))
+	}
+	ncols := max(1, min(len(cells), avail/(widest+padding)))
+	nrows := (len(cells) + ncols - 1) / ncols
+
+	rows := make([][]string, nrows)
+	for i, c := range cells {
+		if ncols == 1 {
+			c = e.truncate(c, avail)
+		}
+		rows[i%nrows] = append(rows[i%nrows], c)
+	}
+	return rows
+}
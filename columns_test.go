@@ -0,0 +1,24 @@
+package linenoisy
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEditor_layoutColumns(t *testing.T) {
+	tests := []struct {
+		cols  int
+		cells []string
+		want  [][]string
+	}{
+		{80, []string{"a", "b"}, [][]string{{"a", "b"}}},
+		{30, []string{"alpha", "bravo", "charlie", "delta", "echo"}, [][]string{{"alpha", "delta"}, {"bravo", "echo"}, {"charlie"}}},
+		{14, []string{"abc", "abcdefghijklmnop"}, [][]string{{"abc"}, {"abcdefgh…"}}},
+	}
+	for _, tt := range tests {
+		e := &Terminal{Cols: tt.cols}
+		if got := e.layoutColumns(tt.cells, 4, 4); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%d %q: expected %q got %q", tt.cols, tt.cells, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package linenoisy
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// pendingHint is shown after the line while CompleteContext is running.
+const pendingHint = "…"
+
+// asyncCompletion is a CompleteContext call running for the line it was started on.
+type asyncCompletion struct {
+	cancel context.CancelFunc
+	line   []rune
+	cur    int
+}
+
+// completeAsync starts CompleteContext in the background and marks the line as pending.
+// The suggestions are applied by the goroutine under edit, only if no key arrived in the meantime;
+// a listing can't wait for the pager's keys there, so it is written at once.
+func (e *Terminal) completeAsync() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &asyncCompletion{cancel: cancel, line: slices.Clone(e.Buffer), cur: e.Cur}
+	e.completion = c
+
+	go func() {
+		start := time.Now()
+		opts := e.CompleteContext(ctx, string(c.line))
+		if e.Metrics != nil {
+			e.Metrics.completed(start)
+		}
+
+		e.edit.Lock()
+		defer e.edit.Unlock()
+		if e.completion != c || ctx.Err() != nil {
+			return
+		}
+		cancel()
+		e.completion = nil
+		if !e.active || e.suspended || !slices.Equal(e.Buffer, c.line) || e.Cur != c.cur {
+			return
+		}
+		// an error of the terminal is reported by the next key
+		before := e.snapshot()
+		e.applyCompletion(candidates(opts, 0, len(e.Buffer)), false)
+		e.notifyChange(before)
+	}()
+
+	return e.refreshLine()
+}
+
+// cancelCompletion abandons the running CompleteContext call and removes its indicator.
+func (e *Terminal) cancelCompletion() error {
+	if e.completion == nil {
+		return nil
+	}
+	e.completion.cancel()
+	e.completion = nil
+	return e.refreshLine()
+}
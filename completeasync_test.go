@@ -0,0 +1,110 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer collects the output written by LineEditor and the completion goroutine.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestCompleteContext(t *testing.T) {
+	r, w := io.Pipe()
+	out := &lockedBuffer{}
+	release := make(chan struct{})
+	e := &Terminal{
+		Inp:    bufio.NewReader(r),
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+		CompleteContext: func(ctx context.Context, line string) []string {
+			if line != "gi" {
+				t.Errorf(`expected "gi" got %#v`, line)
+			}
+			<-release
+			return []string{"git"}
+		},
+	}
+
+	done := make(chan string)
+	go func() {
+		l, _ := e.LineEditor()
+		done <- l
+	}()
+
+	w.Write([]byte("gi\t"))
+	waitFor(t, "the indicator", func() bool { return strings.Contains(out.String(), "> gi\x1b[2m"+pendingHint) })
+	close(release)
+	waitFor(t, "the completion", func() bool {
+		e.edit.Lock()
+		defer e.edit.Unlock()
+		return e.completion == nil && string(e.Buffer) == "git"
+	})
+	w.Write([]byte("\r"))
+
+	if l := <-done; l != "git" {
+		t.Errorf(`expected "git" got %#v`, l)
+	}
+}
+
+func TestCompleteContextCancel(t *testing.T) {
+	r, w := io.Pipe()
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	e := &Terminal{
+		Inp:    bufio.NewReader(r),
+		Out:    bufio.NewWriter(&lockedBuffer{}),
+		Prompt: "> ",
+		CompleteContext: func(ctx context.Context, line string) []string {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+			return []string{"git"}
+		},
+	}
+
+	done := make(chan string)
+	go func() {
+		l, _ := e.LineEditor()
+		done <- l
+	}()
+
+	w.Write([]byte("gi\t"))
+	<-started
+	w.Write([]byte("x"))
+	<-cancelled
+	w.Write([]byte("\r"))
+
+	if l := <-done; l != "gix" {
+		t.Errorf(`expected "gix" got %#v`, l)
+	}
+}
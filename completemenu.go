@@ -0,0 +1,19 @@
+package linenoisy
+
+// completeMenu hands the candidates over to CompleteMenu while rendering is suspended
+// and applies the one it picked.
+func (e *Terminal) completeMenu(cs []Candidate) error {
+	if err := e.suspend(); err != nil {
+		return err
+	}
+	c, ok := e.CompleteMenu(cs)
+	if err := e.resume(); err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	c.ReplaceFrom = min(max(c.ReplaceFrom, 0), len(e.Buffer))
+	c.ReplaceTo = min(max(c.ReplaceTo, c.ReplaceFrom), len(e.Buffer))
+	return e.applyCandidate(c)
+}
@@ -0,0 +1,75 @@
+// Package completers provides ready-made completion functions for the line editor:
+//
+//	e := linenoisy.NewTerminal(c, "> ")
+//	e.CompleteWord = completers.FilePath(".")
+package completers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FilePath returns a CompleteWord function which completes the word under the cursor as a file path.
+// Relative paths start at root and a leading "~" is the home directory. Directories get a trailing
+// slash, so completion can go on inside them; spaces and backslashes are escaped by a backslash.
+// Hidden files are only offered when the typed name starts with a dot.
+func FilePath(root string) func(line string, start, end int) []string {
+	return func(line string, start, end int) []string {
+		typed := unescape(string([]rune(line)[start:end]))
+		if typed == "~" {
+			return []string{"~/"}
+		}
+
+		i := strings.LastIndex(typed, "/") + 1
+		dir, prefix := typed[:i], typed[i:]
+
+		real := dir
+		switch {
+		case strings.HasPrefix(dir, "~/"):
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil
+			}
+			real = filepath.Join(home, dir[2:])
+		case !filepath.IsAbs(dir):
+			real = filepath.Join(root, dir)
+		}
+
+		entries, err := os.ReadDir(real)
+		if err != nil {
+			return nil
+		}
+
+		var opts []string
+		for _, d := range entries {
+			name := d.Name()
+			if !strings.HasPrefix(name, prefix) || strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+				continue
+			}
+			c := escape(dir + name)
+			if fi, err := os.Stat(filepath.Join(real, name)); err == nil && fi.IsDir() {
+				c += "/"
+			}
+			opts = append(opts, c)
+		}
+		return opts
+	}
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, " ", `\ `, "\t", "\\\t")
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func unescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package completers
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFilePath(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"docs", "my dir", ".git"} {
+		if err := os.Mkdir(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"doc.txt", "my dir/notes.md", ".gitignore"} {
+		if err := os.WriteFile(filepath.Join(root, f), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("HOME", root)
+
+	complete := FilePath(root)
+	for _, tt := range []struct {
+		line string
+		want []string
+	}{
+		{"cat doc", []string{"doc.txt", "docs/"}},
+		{"cat ", []string{"doc.txt", "docs/", "my\\ dir/"}},
+		{"cat my", []string{"my\\ dir/"}},
+		{"cat my\\ dir/", []string{"my\\ dir/notes.md"}},
+		{"cat .g", []string{".git/", ".gitignore"}},
+		{"cat ~", []string{"~/"}},
+		{"cat ~/d", []string{"~/doc.txt", "~/docs/"}},
+		{"cat " + root + "/do", []string{root + "/doc.txt", root + "/docs/"}},
+		{"cat missing/", nil},
+	} {
+		rs := []rune(tt.line)
+		start := len([]rune("cat "))
+		if got := complete(tt.line, start, len(rs)); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: expected %q got %q", tt.line, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package linenoisy
+
+// drawnLine is the single row line refreshLine put on the screen last.
+type drawnLine struct {
+	prompt string
+	line   []rune // Buffer followed by the hint.
+	col    int    // cursor column.
+}
+
+// refreshDiff updates the row drawn before to the prompt of width pw followed by line with the cursor at col,
+// rewriting only the runes after the common prefix of the old and the new line.
+func (e *Terminal) refreshDiff(pw int, line []rune, col int) error {
+	old := e.drawn
+
+	k := 0
+	for k < len(line) && k < len(old.line) && line[k] == old.line[k] {
+		k++
+	}
+	k = min(k, len(e.Buffer)) // a changed hint is redrawn from its start, where its styling is.
+
+	ew := &errWriter{w: e.Out}
+	x := old.col
+	if k < len(line) || k < len(old.line) {
+		start := pw + e.width(string(line[:k]))
+		moveCol(ew, e.caps(), x, start)
+
+		ew.writeString(string(line[k:]))
+		x = start + e.width(string(line[k:]))
+		if e.width(string(old.line[k:])) > e.width(string(line[k:])) {
+			ew.writeString(e.caps().ClearEOL)
+		}
+	}
+	moveCol(ew, e.caps(), x, col)
+	ew.flush()
+
+	e.drawn = &drawnLine{prompt: old.prompt, line: line, col: col}
+	return ew.err
+}
+
+// moveCol moves the cursor along the row from column x to column to.
+func moveCol(ew *errWriter, c *Capabilities, x, to int) {
+	switch {
+	case to < x:
+		ew.writeString(c.left(x - to))
+	case to > x:
+		ew.writeString(c.right(to - x))
+	}
+}
@@ -0,0 +1,91 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Joker/linenoisy/vtest"
+)
+
+func TestEditor_DiffRefresh(t *testing.T) {
+	in := bytes.NewBuffer([]byte("abc\x02\x02x\x7f\x01\x0bz\x0d"))
+	out := &checkedWriter{
+		expectations: []string{
+			"\r> \x1b[0K\r\x1b[2C",
+			"a",
+			"b",
+			"c",
+			"\x1b[1D",
+			"\x1b[1D",
+			"xbc\x1b[2D",
+			"\x1b[1Dbc\x1b[0K\x1b[2D",
+			"\x1b[1D",
+			"\x1b[0K",
+			"z",
+		},
+	}
+
+	e := &Terminal{
+		Inp:         bufio.NewReader(in),
+		Out:         bufio.NewWriter(out),
+		Prompt:      "> ",
+		DiffRefresh: true,
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "z" {
+		t.Errorf(`expected "z" got %#v`, l)
+	}
+}
+
+func TestEditor_DiffRefreshScreen(t *testing.T) {
+	keys := "hello world\x02\x02\x02\x7f\x7fXY\x01\x06\x06\x14\x05\x17ab\x15abcdefghijklmnopqrstuvwxyz\x7f\x7f\x7f\x7f\x7f\x7f\x02\x02q"
+	hint := func(line string) string {
+		if strings.HasPrefix(line, "he") {
+			return " <hint>"
+		}
+		return ""
+	}
+
+	render := func(diff bool) (*vtest.Screen, int) {
+		s := vtest.New(20, 4)
+		var n countWriter
+		e := &Terminal{
+			Inp:         bufio.NewReader(bytes.NewBufferString(keys)),
+			Out:         bufio.NewWriter(io.MultiWriter(s, &n)),
+			Prompt:      "> ",
+			Cols:        20,
+			Rows:        4,
+			Hint:        hint,
+			DiffRefresh: diff,
+		}
+		e.LineEditor()
+		return s, int(n)
+	}
+
+	full, fullBytes := render(false)
+	diff, diffBytes := render(true)
+	if full.String() != diff.String() {
+		t.Errorf("expected %q got %q", full.String(), diff.String())
+	}
+	fc, fr := full.Cursor()
+	if dc, dr := diff.Cursor(); dc != fc || dr != fr {
+		t.Errorf("expected cursor %d,%d got %d,%d", fc, fr, dc, dr)
+	}
+	if diffBytes >= fullBytes {
+		t.Errorf("expected less than %d bytes got %d", fullBytes, diffBytes)
+	}
+}
+
+type countWriter int
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	*w += countWriter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,85 @@
+package linenoisy
+
+import (
+	"io"
+	"slices"
+	"strings"
+)
+
+// dumb reports whether the terminal has no cursor control: Dumb is set, or the terminal type, Term or
+// else TERM of a local terminal, is one of SupportedTerms or "unknown".
+func (e *Terminal) dumb() bool {
+	if e.Dumb {
+		return true
+	}
+	term := e.term()
+	return term == "unknown" || slices.Contains(SupportedTerms, term)
+}
+
+// readDumb reads a line on a terminal without cursor control. Typed characters are echoed,
+// Backspace erases the last one with "\b \b" and Ctrl-U all of them; no other editing is possible.
+func (e *Terminal) readDumb() (string, error) {
+	if err := e.waitPeek(); err != nil {
+		return "", err
+	}
+	e.Buffer = e.Buffer[:0]
+	e.Cur = 0
+
+	ew := &errWriter{w: e.Out}
+	ew.writeString(strings.ReplaceAll(stripEscapes(e.Prompt), "\n", "\r\n"))
+	ew.flush()
+	for ew.err == nil {
+		r, _, err := e.Inp.ReadRune()
+		if err != nil {
+			return string(e.Buffer), err
+		}
+
+		switch {
+		case r == enter || r == '\n':
+			return string(e.Buffer), nil
+		case r == 3: // Ctrl-C
+			return string(e.Buffer), ErrInterrupted
+		case r == 4 && len(e.Buffer) == 0: // Ctrl-D
+			return "", io.EOF
+		case r == backspace || r == '\b':
+			if len(e.Buffer) > 0 {
+				e.Buffer = e.Buffer[:len(e.Buffer)-1]
+				e.echo(ew, "\b \b")
+			}
+		case r == 21: // Ctrl-U
+			e.echo(ew, strings.Repeat("\b \b", len(e.Buffer)))
+			e.Buffer = e.Buffer[:0]
+		case r == esc:
+			e.skipEscape()
+		case r >= ' ' && e.room(1) == 0:
+			e.echo(ew, "\a")
+		case r >= ' ':
+			e.Buffer = append(e.Buffer, r)
+			e.echo(ew, string(r))
+		}
+		e.Cur = len(e.Buffer)
+		ew.flush()
+	}
+	return string(e.Buffer), ew.err
+}
+
+// skipEscape discards the rest of an escape sequence, like an arrow key, which has no use without cursor control.
+func (e *Terminal) skipEscape() {
+	r, _, err := e.Inp.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return
+	}
+	for {
+		r, _, err = e.Inp.ReadRune()
+		if err != nil || (r >= '@' && r <= '~' && r != '[') {
+			return
+		}
+	}
+}
+
+// echo writes s unless the line is read by SilentLineEditor.
+func (e *Terminal) echo(ew *errWriter, s string) {
+	if !e.silent {
+		ew.writeString(s)
+	}
+}
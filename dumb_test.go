@@ -0,0 +1,57 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEditor_Dumb(t *testing.T) {
+	for _, tt := range []struct {
+		in, line, out string
+		err           error
+	}{
+		{"ls\r", "ls", "> ls", nil},
+		{"lx\x7fs -l\r", "ls -l", "> lx\b \bs -l", nil},
+		{"rm\x15ls\x1b[A\r", "ls", "> rm\b \b\b \bls", nil},
+		{"ls\x03", "ls", "> ls", ErrInterrupted},
+		{"\x04", "", "> ", io.EOF},
+	} {
+		var out bytes.Buffer
+		e := &Terminal{
+			Inp:    bufio.NewReader(strings.NewReader(tt.in)),
+			Out:    bufio.NewWriter(&out),
+			Prompt: "\x1b[1m>\x1b[0m ",
+			Term:   "dumb",
+		}
+
+		l, err := e.LineEditor()
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%q: expected error %v got %v", tt.in, tt.err, err)
+		}
+		if l != tt.line {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.line, l)
+		}
+		if got := strings.TrimSuffix(out.String(), "\r\n"); got != tt.out {
+			t.Errorf("%q: expected output %q got %q", tt.in, tt.out, got)
+		}
+	}
+}
+
+func TestEditor_AdjustNoReport(t *testing.T) {
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp: bufio.NewReader(strings.NewReader("R")),
+		Out: bufio.NewWriter(&out),
+	}
+
+	if err := e.Adjust(); err == nil {
+		t.Error("expected an error")
+	}
+	if !e.Dumb {
+		t.Error("expected a dumb terminal")
+	}
+}
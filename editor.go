@@ -4,6 +4,7 @@ package linenoisy
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -11,26 +12,14 @@ import (
 	"slices"
 	"strconv"
 	"strings"
-	"text/tabwriter"
+	"sync"
+	"time"
+	"unicode"
 )
 
 const (
-	ctrlA     = 1
-	ctrlB     = 2
-	ctrlC     = 3
-	ctrlD     = 4
-	ctrlE     = 5
-	ctrlF     = 6
-	ctrlH     = 8
 	tab       = 9
-	ctrlK     = 11
-	ctrlL     = 12
 	enter     = 13
-	ctrlN     = 14
-	ctrlP     = 16
-	ctrlT     = 20
-	ctrlU     = 21
-	ctrlW     = 23
 	esc       = 27
 	backspace = 127
 )
@@ -45,8 +34,13 @@ var (
 	Cyan    = []byte{esc, '[', '3', '6', 'm'}
 	White   = []byte{esc, '[', '3', '7', 'm'}
 	Reset   = []byte{esc, '[', '0', 'm'}
+	Bold    = []byte{esc, '[', '1', 'm'}
+	Dim     = []byte{esc, '[', '2', 'm'}
 
-	SupportedTerms = []string{"dumb", "cons25", "emacs"} // SupportedTerms is a list of supported terminals.
+	// ErrInterrupted is returned by LineEditor when the user pressed Ctrl-C, Ctrl-D on an empty line returns io.EOF.
+	ErrInterrupted = errors.New("interrupted")
+
+	SupportedTerms = []string{"dumb", "emacs"} // SupportedTerms are the terminal types without cursor control, LineEditor reads plain lines on them.
 	curPosPattern  = regexp.MustCompile("\x1b\\[(\\d+);(\\d+)R")
 )
 
@@ -56,21 +50,160 @@ type Terminal struct {
 	Out *bufio.Writer
 	Raw io.ReadWriteCloser
 
+	out io.Writer // transport side of Out, including middlewares installed by Use.
+
+	// Deprecated: use CurrentPrompt and SetPrompt; the exported field is kept for one release.
 	Prompt string
 
-	Buffer  []rune // keeps the current user input.
-	Cur     int    // current cursor position in Buffer.
-	OldCur  int    // previous cursor position in Buffer.
-	Cols    int    // width  default 80.
-	Rows    int    // height default 24.
-	MaxRows int    // height of editor status on the terminal.
+	ContPrompt      string // OPTIONAL; Printed at the beginning of every wrapped row of the input.
+	AlignContPrompt bool   // OPTIONAL; Pads ContPrompt to the visual width of Prompt so wrapped columns line up.
+
+	// Buffer keeps the current user input.
+	//
+	// Deprecated: use Line and SetLine; the exported field is kept for one release.
+	Buffer []rune
+
+	// Cur is the current cursor position in Buffer.
+	//
+	// Deprecated: use CursorPos and SetCursorPos; the exported field is kept for one release.
+	Cur int
+
+	OldCur int // previous cursor position in Buffer.
+
+	// Cols is the width of the terminal, default 80.
+	//
+	// Deprecated: use Size and SetSize; the exported field is kept for one release.
+	Cols int
+
+	// Rows is the height of the terminal, default 24.
+	//
+	// Deprecated: use Size and SetSize; the exported field is kept for one release.
+	Rows int
+
+	MaxRows int // height of editor status on the terminal.
+
+	curRow    int  // cursor row within the editor region after the last refresh.
+	suspended bool // rendering is parked by Suspend.
+	active    bool // LineEditor is running.
+
+	altScreen    bool // the alternate screen is shown, see EnterAltScreen.
+	altSuspended bool // rendering was suspended before EnterAltScreen.
+
+	drawn *drawnLine // the line on the screen for DiffRefresh, nil when unknown.
+
+	bindings    map[Key]func(*Terminal) error // set by Bind.
+	killRing    []string                      // killed text, the most recent last.
+	clipboard   string                        // the content of the last KeyClipboard.
+	pasted      string                        // the content of the last KeyPaste.
+	peek        chan error                    // a wait for input after ESC still running, see waitInput.
+	seqDeadline time.Time                     // the rest of the escape sequence being read is given up after it, see SeqTimeout.
+	seqLost     rune                          // the introducer of an escape sequence given up by SeqTimeout, whose late rest is discarded, see lostRune.
+
+	mark      int  // the other end of the region, see region.
+	marked    bool // a region is selected.
+	shiftMark bool // the region was started by a Shift motion, a plain motion deselects it.
+
+	completion *asyncCompletion // the running CompleteContext call.
+	hinting    *asyncHint       // the HintContext call of the current line.
+	expanded   *abbrevExpansion // made by the previous key.
+	lastArg    *lastArgYank     // the last yank-last-arg.
+	keyCount   int              // keys handled, to tell whether the previous one was a yank-last-arg.
+
+	historyUsed bool          // the line came from history, see LineResult.
+	silent      bool          // the line is read by SilentLineEditor.
+	feed        *feeder       // runs LineEditor on the input of Feed.
+	stream      chan KeyEvent // keys decoded by KeyEvents.
+	commands    chan string   // commands injected with Commands.
+	queued      []string      // commands which came while a key read more keys, see streamKey.
+	stuff       *stuffReader  // input queued by Stuff.
+
+	status      string // set by SetStatus.
+	statusShown bool   // status is drawn on the row above the edit region.
+	barRows     int    // the terminal height the StatusBar scroll region was set for, 0 without a bar.
+	counted     bool   // the output goes through a counter of Metrics.
+
+	handling       bool        // LineEditor is handling a key it read.
+	lastRefresh    time.Time   // of the last refresh drawn, see RefreshInterval.
+	refreshPending bool        // a refresh was skipped by RefreshInterval or TypeaheadHold.
+	lastOut        time.Time   // of the last output of WriteOut, see TypeaheadHold.
+	holdTimer      *time.Timer // draws the keys held back by TypeaheadHold.
+
+	edit sync.Mutex // serializes key handling in LineEditor with WriteOut, Write, the Elapsed indicator, Suspend, Resume and SetSize.
+	outq outQueue   // output of WriteOut calls waiting for edit.
+
+	ticker *elapsedTicker // running between Begin and End.
+
+	sizeMu      sync.Mutex
+	pendingSize [2]int // cols and rows of a SetSize waiting for edit.
 
 	History History
 
-	Complete  func(line string) []string    // OPTIONAL; It takes the current user input and returns some completion suggestions.
-	Help      func(line string) [][2]string // OPTIONAL; Print help.
-	Hint      func(line string) string      // OPTIONAL; Hint will be called while user is typing and displayed on the right of the user input.
-	WidthChar func(rune) int                // OPTIONAL; Calculates character width on the terminal. (A lot of CJK characters and emojis are twice as wide as ASCII characters.)
+	Elapsed func(d time.Duration) string // OPTIONAL; Renders a live indicator on its own line while the application executes a line (between Begin and End).
+
+	HistoryExpansion    bool // OPTIONAL; Enter expands !!, !n, !-n and !prefix history references.
+	HistoryVerify       bool // OPTIONAL; An expanded line is put back for editing instead of being returned.
+	HistoryPrefixSearch bool // OPTIONAL; Up and Down only visit history entries starting with the text before the cursor.
+	BracketedPaste      bool // OPTIONAL; Enables bracketed paste, so a multi-line paste is previewed instead of submitted line by line.
+	FuzzyComplete       bool // OPTIONAL; Candidates of Complete or CompleteWord are filtered and ranked by a fuzzy match of the text before the cursor (see FuzzyFilter), the matched characters are highlighted in the listing.
+	CompleteDedup       bool // OPTIONAL; Candidates with the same text and range are listed once.
+	CompleteHideCurrent bool // OPTIONAL; Candidates which wouldn't change the line, like the word already typed, are dropped.
+	Overwrite           bool // OPTIONAL; Typed characters replace the character under the cursor. The Insert key toggles it.
+	MatchBrackets       bool // OPTIONAL; The bracket matching the one at the cursor is shown in bold, which helps with nested expressions.
+	DiffRefresh         bool // OPTIONAL; Redraws only the changed part of a single row line instead of the whole line, which saves bandwidth and flicker on slow links.
+
+	CompleteOrder CompleteOrder // OPTIONAL; Sorts the candidates before they are listed, by default they keep the order of the completer.
+
+	WordBreakChars string // OPTIONAL; The runes separating words for the word motions, Alt-Backspace, Alt-d and the word CompleteWord completes; by default words are runs of letters, digits and '_', and the completed word is delimited by DefaultWordBreakChars.
+
+	MaxLineLen int // OPTIONAL; Insertions which would make the line longer than this many runes are cut off with a bell, so a client can't paste megabytes into Buffer.
+
+	Clipboard bool // OPTIONAL; Killed and copied text also goes to the clipboard of the terminal with OSC 52, which reaches the local clipboard over SSH. Ctrl-X Ctrl-W copies the whole line, Ctrl-X Ctrl-Y pastes the clipboard if the terminal allows reading it.
+
+	KittyKeyboard bool // OPTIONAL; Enables the kitty keyboard protocol while editing, so modified keys like Ctrl-Enter, Shift-Tab and Ctrl-Shift-A arrive as their own keys which can be bound. Terminals without it keep sending the usual sequences.
+
+	Mouse bool // OPTIONAL; Enables mouse reporting while editing, the wheel scrolls through history. The terminal's own text selection then needs Shift held.
+
+	EscTimeout      time.Duration // OPTIONAL; A lone ESC not followed by more input within this time is the Escape key, which can be bound; by default ESC waits for the rest of a sequence.
+	SeqTimeout      time.Duration // OPTIONAL; An escape sequence not complete within this time after its ESC is given up and ignored, its rest is discarded when it arrives late, so a lost or delayed segment on a slow link neither blocks the editor nor turns into typed text; by default the rest is awaited.
+	HintDelay       time.Duration // OPTIONAL; HintContext only runs after the line stayed unchanged for this time.
+	TypeaheadHold   time.Duration // OPTIONAL; Keys typed while WriteOut output keeps coming, less than this time apart, are applied but not drawn on their own; the output redraws them, or the line is redrawn once the output has paused this long. Typing then doesn't interleave with streaming output.
+	RefreshInterval time.Duration // OPTIONAL; While keys arrive faster, the line is redrawn at most once in this time, e.g. 33ms for slow serial consoles; it is always redrawn once the input has been caught up with.
+
+	Abbrevs map[string]string // OPTIONAL; An abbreviation typed as the first word of the line is replaced by its expansion on Space or Enter, Ctrl-_ right after the Space puts it back.
+
+	AutoPairs map[rune]rune // OPTIONAL; Typing an opening rune also inserts its closing one, typing a closing rune steps over the same one, Backspace removes an empty pair; see DefaultAutoPairs.
+
+	Caps       *Capabilities // OPTIONAL; The control sequences to draw with, defaults to Terminfo of Term or ANSI.
+	Term       string        // OPTIONAL; The terminal type of the client, e.g. from an SSH pty-req; defaults to TERM for a local terminal.
+	NoAutoWrap bool          // OPTIONAL; The terminal doesn't wrap at the right margin, e.g. a serial console with DECAWM off, so the rows of a long line are ended explicitly. Implied by Caps without am.
+	Dumb       bool          // OPTIONAL; The terminal has no cursor control, LineEditor only echoes and handles Backspace. Set for a Term in SupportedTerms and when Adjust gets no cursor position report.
+
+	Features *Features // OPTIONAL; The capabilities found by Probe; the paste and mouse modes, Foreground and auto-wrap are only used if they are supported.
+
+	Metrics *Metrics // OPTIONAL; Counts keys, refreshes, output bytes and completions, e.g. to monitor the editor overhead of a server.
+
+	Interactive *bool // OPTIONAL; Overrides the detection of a non-terminal Raw (pipe, file) which makes LineEditor read plain lines.
+
+	ModeChanged  func(overwrite bool)                    // OPTIONAL; Called after the Insert key toggled Overwrite, e.g. to show the mode in the prompt.
+	ExternalEdit func(current string) (string, error)    // OPTIONAL; Edits the line in a full editor on Ctrl-X Ctrl-E while rendering is suspended; see EditInEditor.
+	CompleteMenu func(cs []Candidate) (Candidate, bool)  // OPTIONAL; Presents multiple completion candidates instead of the listing, e.g. in a panel of the application, while rendering is suspended; the returned candidate is applied unless ok is false.
+	OnChange     func(line string, pos int)              // OPTIONAL; Called after a key or a completion changed Buffer or Cur, with the new content and cursor position in runes, e.g. to update a preview.
+	PreSubmit    func(line string) string                // OPTIONAL; Called on Enter, the returned line is accepted instead, e.g. trimmed or with aliases expanded.
+	PostSubmit   func(line string)                       // OPTIONAL; Called with the accepted line after it has been drawn for the last time.
+	OnKey        func(key Key) (handled bool, err error) // OPTIONAL; Called for every key before the bindings and the built-in keys. A handled key isn't processed further, an error ends LineEditor with it, e.g. to veto Ctrl-D or add shortcuts of the application.
+
+	Complete        func(line string) []string                      // OPTIONAL; It takes the current user input and returns some completion suggestions.
+	CompleteWord    func(line string, start, end int) []string      // OPTIONAL; Used instead of Complete, it takes the user input with the rune offsets of the word under the cursor and returns replacements for just that word.
+	CompleteRange   func(line string, pos int) []Candidate          // OPTIONAL; Used instead of Complete and CompleteWord, it takes the user input with the cursor position and returns candidates which replace a range of their own, e.g. the text after the last '/' or an abbreviation in the middle of the line.
+	CompleteContext func(ctx context.Context, line string) []string // OPTIONAL; Used instead of Complete and CompleteWord for slow lookups: it runs in the background while the user keeps editing, ctx is cancelled on the next key and the suggestions only apply to an unchanged line.
+	Help            func(line string) [][2]string                   // OPTIONAL; Print help on F1 or Alt-h.
+	TransientPrompt func(line string) string                        // OPTIONAL; Once a line is accepted it is redrawn plainly behind the returned prompt, without hint and highlighting, so long prompts don't fill the scrollback.
+	StatusBar       func() string                                   // OPTIONAL; Returns the text of a bar pinned to the last row of the terminal, e.g. connection info or a mode, asked after every key.
+	Suggest         func(line string) []string                      // OPTIONAL; Called on Enter, the returned corrections of the line are offered in a "did you mean" list before the line is accepted.
+	Hint            func(line string) string                        // OPTIONAL; Hint will be called while user is typing and displayed on the right of the user input.
+	HintContext     func(ctx context.Context, line string) string   // OPTIONAL; Used instead of Hint for slow hints: it runs in the background, ctx is cancelled when the line changes and the hint is displayed when it arrives.
+	HintStyle       []byte                                          // OPTIONAL; The SGR sequence the hint is drawn in, so it doesn't look like typed input; defaults to Dim, an empty non-nil slice draws the hint as it is.
+	WidthChar       func(rune) int                                  // OPTIONAL; Calculates character width on the terminal. Defaults to East Asian Width: CJK characters and emojis are twice as wide as ASCII characters, combining marks take no room.
 }
 
 func NewTerminal(channel io.ReadWriteCloser, prompt string) *Terminal {
@@ -78,6 +211,7 @@ func NewTerminal(channel io.ReadWriteCloser, prompt string) *Terminal {
 		Inp:    bufio.NewReader(channel),
 		Out:    bufio.NewWriter(channel),
 		Raw:    channel,
+		out:    channel,
 		Prompt: prompt,
 		Cols:   80,
 		Rows:   24,
@@ -85,121 +219,365 @@ func NewTerminal(channel io.ReadWriteCloser, prompt string) *Terminal {
 }
 
 // LineEditor reads user key strokes and returns a confirmed input line while displaying editor states on the terminal.
+// Ctrl-C returns ErrInterrupted and Ctrl-D on an empty line io.EOF, with the line as it was.
 func (e *Terminal) LineEditor() (string, error) {
-	if err := e.LineReset(); err != nil {
+	if !e.interactive() {
+		return e.readLine()
+	}
+	if e.dumb() {
+		return e.readDumb()
+	}
+
+	err := e.begin()
+	defer e.end()
+	if err != nil {
 		return string(e.Buffer), err
 	}
 
 	for {
-		r, _, err := e.Inp.ReadRune()
+		k, err := e.readKey()
 		if err != nil {
 			return string(e.Buffer), err
 		}
+		l, done, err := e.step(k)
+		if done || err != nil {
+			return l, err
+		}
+	}
+}
 
-		switch r {
-		case enter:
-			return string(e.Buffer), nil
-		case tab:
-			err = e.completeLine()
-		case '?':
-			err = e.printHelp()
-		case backspace, ctrlH:
-			err = e.editBackspace()
-		case ctrlC:
-			return string(e.Buffer), errors.New("try again")
-		case ctrlD:
-			if len(e.Buffer) == 0 {
-				return string(e.Buffer), io.EOF
-			}
-			err = e.editDelete()
-		case esc:
-			r1, _, err := e.Inp.ReadRune()
-			if err != nil {
-				return string(e.Buffer), err
-			}
+// begin switches the terminal modes of the editor on and draws the prompt of a new line.
+func (e *Terminal) begin() error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
 
-			switch r1 {
-			case '[':
-				r2, _, err := e.Inp.ReadRune()
-				if err != nil {
-					return string(e.Buffer), err
-				}
+	e.active = true
+	e.historyUsed = false
+	var err error
+	if e.bracketedPaste() {
+		err = e.writeSeq("\x1b[?2004h")
+	}
+	if e.mouse() && err == nil {
+		err = e.writeSeq("\x1b[?1000h\x1b[?1006h")
+	}
+	if e.kittyKeyboard() && err == nil {
+		err = e.writeSeq("\x1b[>1u")
+	}
+	if err == nil && e.status != "" {
+		ew := &errWriter{w: e.Out}
+		e.writeStatus(ew)
+		ew.flush()
+		err = ew.err
+	}
+	if err == nil && e.Metrics != nil {
+		err = e.countOutput()
+	}
+	if err == nil {
+		err = e.refreshStatusBar()
+	}
+	if err == nil {
+		err = e.LineReset()
+	}
+	return err
+}
 
-				switch r2 {
-				case '0', '1', '2', '4', '5', '6', '7', '8', '9':
-					_, _, err = e.Inp.ReadRune()
-				case '3':
-					r4, _, err := e.Inp.ReadRune()
-					if err != nil {
-						return string(e.Buffer), err
-					}
-
-					if r4 == '~' {
-						err = e.editDelete()
-					}
-				case 'A':
-					err = e.editHistoryPrev()
-				case 'B':
-					err = e.editHistoryNext()
-				case 'C':
-					err = e.editMoveRight()
-				case 'D':
-					err = e.editMoveLeft()
-				case 'H':
-					err = e.editMoveHome()
-				case 'F':
-					err = e.editMoveEnd()
-				}
-			case 'O':
-				r3, _, err := e.Inp.ReadRune()
-				if err != nil {
-					return string(e.Buffer), err
-				}
+// end switches off what begin switched on, once the line is done.
+func (e *Terminal) end() {
+	e.edit.Lock()
+	defer e.edit.Unlock()
 
-				switch r3 {
-				case 'H':
-					err = e.editMoveHome()
-				case 'F':
-					err = e.editMoveEnd()
-				}
-			}
-		case ctrlL:
-			if err := e.clearScreen(); err != nil {
-				return string(e.Buffer), err
+	if e.completion != nil {
+		e.completion.cancel()
+		e.completion = nil
+	}
+	if e.hinting != nil {
+		e.hinting.cancel()
+		e.hinting = nil
+	}
+	e.stopHold()
+	if !e.active {
+		return // switched off by Close
+	}
+	if e.bracketedPaste() {
+		e.writeSeq("\x1b[?2004l")
+	}
+	if e.mouse() {
+		e.writeSeq("\x1b[?1006l\x1b[?1000l")
+	}
+	if e.kittyKeyboard() {
+		e.writeSeq("\x1b[<u")
+	}
+	e.hideStatus()
+	e.active = false
+}
+
+// step handles k with the refreshes, notifications and the submission of the line around it.
+func (e *Terminal) step(k Key) (string, bool, error) {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	before := e.snapshot()
+	var (
+		l    string
+		done bool
+		err  error
+	)
+	e.handling = true
+	run := e.readRun(k)
+	if len(run) > 1 {
+		err = e.insertRun(run)
+		l = string(e.Buffer)
+	} else {
+		l, done, err = e.handleKey(k)
+	}
+	e.handling = false
+	if err == nil && (done || e.buffered() == 0 && !e.outputBurst()) {
+		err = e.flushRefresh()
+	}
+	if e.Metrics != nil {
+		e.Metrics.keys.Add(int64(max(len(run), 1)))
+	}
+	if done && err == nil {
+		l, err = e.submit(l)
+	}
+	e.notifyChange(before)
+	if err == nil && !e.suspended {
+		err = e.refreshStatusBar()
+	}
+	return l, done, err
+}
+
+// handleKey applies k to the edit state and reports whether the line is done.
+func (e *Terminal) handleKey(k Key) (string, bool, error) {
+	e.keyCount++
+	if err := e.cancelCompletion(); err != nil {
+		return string(e.Buffer), true, err
+	}
+
+	expanded := e.expanded
+	e.expanded = nil
+
+	if e.OnKey != nil {
+		handled, err := e.OnKey(k)
+		if err != nil {
+			return string(e.Buffer), true, err
+		}
+		if handled {
+			return string(e.Buffer), false, nil
+		}
+	}
+	if e.silent {
+		return e.handleSilentKey(k)
+	}
+
+	if f, ok := e.bindings[k]; ok {
+		err := f(e)
+		return string(e.Buffer), err != nil, err
+	}
+
+	k, err := e.selectKey(k)
+	if err != nil {
+		return string(e.Buffer), true, err
+	}
+
+	switch k {
+	case Key{Code: KeyEnter}:
+		if e.expandAbbrev() {
+			if err := e.refreshLine(); err != nil {
+				return string(e.Buffer), true, err
 			}
-			err = e.refreshLine()
-		case ctrlW:
-			err = e.editDeletePrevWord()
-		case ctrlB:
-			err = e.editMoveLeft()
-		case ctrlF:
-			err = e.editMoveRight()
-		case ctrlP:
-			err = e.editHistoryPrev()
-		case ctrlN:
-			err = e.editHistoryNext()
-		case ctrlU:
-			err = e.LineReset()
-		case ctrlK:
-			err = e.editKillForward()
-		case ctrlA:
-			err = e.editMoveHome()
-		case ctrlE:
-			err = e.editMoveEnd()
-		case ctrlT:
-			err = e.editSwap()
-		default:
-			err = e.editInsert(r)
+		}
+		if !e.HistoryExpansion {
+			return e.suggest()
 		}
 
+		l, err := e.History.Expand(string(e.Buffer))
 		if err != nil {
-			return string(e.Buffer), err
+			if err := e.beep(); err != nil {
+				return string(e.Buffer), true, err
+			}
+			return string(e.Buffer), false, nil
+		}
+		if l == string(e.Buffer) {
+			return e.suggest()
+		}
+
+		e.Buffer = []rune(l)
+		e.Cur = len(e.Buffer)
+		e.historyUsed = true
+		if err := e.refreshLine(); err != nil {
+			return l, true, err
+		}
+		if !e.HistoryVerify {
+			return e.suggest()
 		}
+	case Key{Code: KeyTab}:
+		err = e.completeLine()
+	case Key{Code: KeyF1}, Key{Rune: 'h', Mod: ModAlt}:
+		err = e.printHelp()
+	case ctrl('v'):
+		err = e.editQuotedInsert()
+	case Key{Code: KeyBackspace}, ctrl('h'):
+		err = e.editBackspace()
+	case ctrl('c'):
+		return string(e.Buffer), true, ErrInterrupted
+	case ctrl('d'):
+		if len(e.Buffer) == 0 {
+			return string(e.Buffer), true, io.EOF
+		}
+		err = e.editDelete()
+	case Key{Code: KeyPaste}:
+		err = e.editPaste()
+	case Key{Code: KeyClipboard}:
+		err = e.insertClipboard()
+	case Key{Code: KeyDelete}:
+		err = e.editDelete()
+	case Key{Code: KeyInsert}:
+		e.Overwrite = !e.Overwrite
+		if e.ModeChanged != nil {
+			e.ModeChanged(e.Overwrite)
+		}
+		err = e.refreshLine()
+	case Key{Code: KeyUp}:
+		err = e.editHistorySearch(-1)
+	case Key{Code: KeyDown}:
+		err = e.editHistorySearch(+1)
+	case Key{Code: KeyPageUp}:
+		err = e.editHistoryFirst()
+	case Key{Code: KeyPageDown}:
+		err = e.editHistoryLast()
+	case Key{Code: KeyWheelUp}:
+		err = e.editHistoryPrev()
+	case Key{Code: KeyWheelDown}:
+		err = e.editHistoryNext()
+	case Key{Code: KeyRight}, ctrl('f'):
+		err = e.editMoveRight()
+	case Key{Code: KeyLeft}, ctrl('b'):
+		err = e.editMoveLeft()
+	case Key{Code: KeyRight, Mod: ModCtrl}, Key{Code: KeyRight, Mod: ModAlt}, Key{Rune: 'f', Mod: ModAlt}:
+		err = e.editWordRight()
+	case Key{Code: KeyLeft, Mod: ModCtrl}, Key{Code: KeyLeft, Mod: ModAlt}, Key{Rune: 'b', Mod: ModAlt}:
+		err = e.editWordLeft()
+	case Key{Code: KeyHome}, ctrl('a'):
+		err = e.editMoveHome()
+	case Key{Code: KeyEnd}, ctrl('e'):
+		err = e.editMoveEnd()
+	case ctrl('l'):
+		if err := e.clearScreen(); err != nil {
+			return string(e.Buffer), true, err
+		}
+		err = e.refreshLine()
+	case ctrl('w'):
+		if e.marked {
+			err = e.editKillRegion()
+			break
+		}
+		err = e.editDeletePrevWord()
+	case ctrl(']'):
+		err = e.editCharSearch(+1)
+	case Key{Rune: ']', Mod: ModCtrl | ModAlt}:
+		err = e.editCharSearch(-1)
+	case Key{Rune: '.', Mod: ModAlt}:
+		err = e.editYankLastArg()
+	case Key{Rune: 'd', Mod: ModAlt}:
+		err = e.editDeleteNextWord()
+	case Key{Code: KeyBackspace, Mod: ModAlt}, Key{Rune: 'h', Mod: ModCtrl | ModAlt}:
+		err = e.editBackwardKillWord()
+	case Key{Rune: 'w', Mod: ModAlt}:
+		err = e.editCopyRegion()
+	case ctrl(' '):
+		err = e.editSetMark()
+	case ctrl('p'):
+		err = e.editHistoryPrev()
+	case ctrl('n'):
+		err = e.editHistoryNext()
+	case ctrl('u'):
+		err = e.editKillBackward()
+	case ctrl('y'):
+		err = e.editYank()
+	case ctrl('x'):
+		var next Key
+		if next, err = e.readKey(); err != nil {
+			break
+		}
+		switch next {
+		case ctrl('e'):
+			err = e.editExternal()
+		case ctrl('w'):
+			err = e.editCopyLine()
+		case ctrl('y'):
+			err = e.editPasteClipboard()
+		case ctrl('r'):
+			err = e.editBrowseHistory()
+		case ctrl('g'):
+			err = e.beep()
+		}
+	case ctrl('g'):
+		err = e.editAbort()
+	case ctrl('z'):
+		err = e.editStop()
+	case ctrl('r'), ctrl('s'):
+		dir := -1
+		if k == ctrl('s') {
+			dir = 1
+		}
+		next, ok, err := e.editIncrementalSearch(dir)
+		if err != nil || !ok {
+			return string(e.Buffer), err != nil, err
+		}
+		return e.handleKey(next)
+	case Key{Rune: ' '}:
+		err = e.editInsertSpace()
+	case ctrl('/'):
+		err = e.editUndoExpansion(expanded)
+	case ctrl('k'):
+		err = e.editKillForward()
+	case ctrl('t'):
+		err = e.editSwap()
+	default:
+		if k.Code == KeyRune && k.Mod == 0 {
+			err = e.editInsert(k.Rune)
+		}
+	}
+
+	return string(e.Buffer), err != nil, err
+}
+
+func (e *Terminal) interactive() bool {
+	if e.Interactive != nil {
+		return *e.Interactive
 	}
+	if f, ok := e.Raw.(interface{ Fd() uintptr }); ok {
+		return isTerminal(f.Fd())
+	}
+	return true
+}
+
+// readLine reads a line without echo and escape sequences for non-interactive input.
+func (e *Terminal) readLine() (string, error) {
+	l, err := e.readReply('\n')
+	if err == io.EOF && l != "" {
+		err = nil
+	}
+	l = strings.TrimSuffix(strings.TrimSuffix(l, "\n"), "\r")
+
+	e.Buffer = []rune(l)
+	e.Cur = len(e.Buffer)
+	return l, err
 }
 
 // Adjust queries the terminal about rows and cols and updates Editor's Rows and Cols.
+// For a Raw which is a local terminal the size comes from the system, remote terminals
+// are asked for the cursor position after moving it to the bottom right corner.
 func (e *Terminal) Adjust() error {
+	if f, ok := e.Raw.(interface{ Fd() uintptr }); ok && isTerminal(f.Fd()) {
+		if c, r, err := termSize(f.Fd()); err == nil {
+			e.Cols = c
+			e.Rows = r
+			return nil
+		}
+	}
+
 	// https://groups.google.com/forum/#!topic/comp.os.vms/bDKSY6nG13k
 	if _, err := e.Out.WriteString("\x1b7\x1b[999;999H\x1b[6n"); err != nil {
 		return err
@@ -209,12 +587,16 @@ func (e *Terminal) Adjust() error {
 		return err
 	}
 
-	res, err := e.Inp.ReadString('R')
+	res, err := e.readReply('R')
 	if err != nil {
 		return err
 	}
 
 	ms := curPosPattern.FindStringSubmatch(res)
+	if ms == nil {
+		e.Dumb = true
+		return fmt.Errorf("no cursor position report in %q", res)
+	}
 	r, err := strconv.Atoi(ms[1])
 	if err != nil {
 		return err
@@ -234,19 +616,70 @@ func (e *Terminal) Adjust() error {
 	return nil
 }
 
+// WriteOut prints b above the edit region and redraws the prompt, Buffer, cursor and hint below it.
+// Output which doesn't end with a newline is terminated, so the prompt always starts on its own row.
+// It is safe to call from other goroutines while LineEditor is running, but not from its callbacks.
+// Calls waiting for each other are coalesced, the whole burst is printed before a single redraw.
 func (e *Terminal) WriteOut(b []byte) (int, error) {
+	n := e.outq.push(b)
+
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	out, ok := e.outq.take(n)
+	if !ok {
+		// printed by the call which held the lock before
+		if err := e.outq.lastErr(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	err := e.writeOut(out)
+	e.outq.setErr(err)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (e *Terminal) writeOut(out []byte) error {
 	e.notZero()
-	ew := errWriter{w: e.Out}
-	ew.writeString("\r\x1b[0K")
-	ew.write(bytes.ReplaceAll(b, []byte("\n"), []byte("\r\n")))
+	ew := &errWriter{w: e.Out}
+	if !e.suspended {
+		e.clearRegion(ew)
+		e.clearStatus(ew)
+	}
+	ew.write(out)
+	if !e.suspended {
+		e.writeStatus(ew)
+	}
 	ew.flush()
 	if ew.err != nil {
-		return 0, ew.err
+		return ew.err
 	}
-	return len(b), e.refreshLine()
+
+	if e.TypeaheadHold > 0 {
+		e.lastOut = time.Now()
+	}
+	e.refreshPending = false // drawn now
+
+	e.OldCur = 0
+	e.MaxRows = 0
+	e.curRow = 0
+	return e.refreshLine()
 }
 
 func (e *Terminal) Write(buf []byte) (written int, err error) {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	if e.ticker != nil && e.ticker.shown {
+		if _, err := e.transport().Write([]byte("\r" + e.caps().ClearEOL)); err != nil {
+			return 0, err
+		}
+	}
+
 	for len(buf) > 0 {
 		todo := len(buf)
 
@@ -255,7 +688,7 @@ func (e *Terminal) Write(buf []byte) (written int, err error) {
 			todo = i
 		}
 
-		nn, err := e.Raw.Write(buf[:todo])
+		nn, err := e.transport().Write(buf[:todo])
 		written += nn
 		if err != nil {
 			return written, err
@@ -264,7 +697,7 @@ func (e *Terminal) Write(buf []byte) (written int, err error) {
 		buf = buf[todo:]
 
 		if i >= 0 {
-			if _, err = e.Raw.Write([]byte{'\r', '\n'}); err != nil {
+			if _, err = e.transport().Write([]byte{'\r', '\n'}); err != nil {
 				return written, err
 			}
 			written++
@@ -274,12 +707,102 @@ func (e *Terminal) Write(buf []byte) (written int, err error) {
 	return written, nil
 }
 
+// Suspend clears the edit region and stops rendering, so the transport can be handed over
+// to a subcommand (pager, editor, shell). Buffer and cursor are kept for Resume.
+func (e *Terminal) Suspend() error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	return e.suspend()
+}
+
+func (e *Terminal) suspend() error {
+	if e.suspended {
+		return nil
+	}
+
+	ew := &errWriter{w: e.Out}
+	e.clearRegion(ew)
+	e.clearStatus(ew)
+	e.removeStatusBar(ew)
+	ew.flush()
+
+	e.suspended = true
+	return ew.err
+}
+
+// clearRegion moves the cursor to the beginning of the edit region and erases all its rows.
+func (e *Terminal) clearRegion(ew *errWriter) {
+	e.drawn = nil
+	if e.curRow == 0 && e.MaxRows == 0 {
+		ew.writeString("\r" + e.caps().ClearEOL)
+		return
+	}
+	if e.curRow > 0 {
+		ew.writeString(e.caps().up(e.curRow))
+	}
+	ew.writeString("\r" + e.caps().ClearEOS)
+}
+
+// leaveRegion forgets the edit region drawn before, output below it scrolls it away
+// and the next refresh starts a new one.
+func (e *Terminal) leaveRegion() {
+	e.drawn = nil
+	e.statusShown = false
+	e.MaxRows = 0
+	e.curRow = 0
+}
+
+// Resume redraws the prompt and the kept Buffer at the current cursor position after Suspend.
+func (e *Terminal) Resume() error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	return e.resume()
+}
+
+func (e *Terminal) resume() error {
+	e.suspended = false
+	e.notZero()
+	e.drawn = nil
+	ew := &errWriter{w: e.Out}
+	e.writeStatus(ew)
+	ew.flush()
+	if ew.err != nil {
+		return ew.err
+	}
+	if err := e.refreshStatusBar(); err != nil {
+		return err
+	}
+	e.OldCur = 0
+	e.MaxRows = 0
+	e.curRow = 0
+	return e.refreshLine()
+}
+
+// redrawTransient replaces the edit region by the accepted line behind the TransientPrompt.
+func (e *Terminal) redrawTransient(line string) error {
+	if e.suspended {
+		return nil
+	}
+	ew := &errWriter{w: e.Out}
+	e.clearRegion(ew)
+	e.clearStatus(ew)
+	ew.writeString(e.TransientPrompt(line))
+	ew.writeString(line)
+	ew.flush()
+	return ew.err
+}
+
 func (e *Terminal) LineReset() error {
 	e.notZero()
+	e.drawn = nil
 	e.Buffer = []rune{}
+	e.marked = false
 	e.OldCur = 0
 	e.Cur = 0
 	e.MaxRows = 0
+	e.curRow = 0
 	return e.refreshLine()
 }
 
@@ -295,36 +818,47 @@ func (e *Terminal) notZero() {
 }
 
 func (e *Terminal) editBackspace() error {
+	if from, to, ok := e.region(); ok {
+		return e.deleteRegion(from, to)
+	}
 	if e.Cur == 0 {
 		return e.beep()
 	}
-	e.Cur--
-	e.Buffer = e.Buffer[:e.Cur+copy(e.Buffer[e.Cur:], e.Buffer[e.Cur+1:])] // Delete https://github.com/golang/go/wiki/SliceTricks
+	if ok, err := e.autoUnpair(); ok {
+		return err
+	}
+	s := graphemeStart(e.Buffer, e.Cur)
+	e.Buffer = slices.Delete(e.Buffer, s, e.Cur)
+	e.Cur = s
 	return e.refreshLine()
 }
 
 func (e *Terminal) editDelete() error {
+	if from, to, ok := e.region(); ok {
+		return e.deleteRegion(from, to)
+	}
 	if e.Cur == len(e.Buffer) {
 		return e.beep()
 	}
-	e.Buffer = e.Buffer[:e.Cur+copy(e.Buffer[e.Cur:], e.Buffer[e.Cur+1:])] // Delete https://github.com/golang/go/wiki/SliceTricks
+	e.Buffer = slices.Delete(e.Buffer, e.Cur, graphemeEnd(e.Buffer, e.Cur))
 	return e.refreshLine()
 }
 
 func (e *Terminal) editSwap() error {
 	p := e.Cur
 	if p == len(e.Buffer) {
-		p = len(e.Buffer) - 1
+		p = graphemeStart(e.Buffer, len(e.Buffer))
 	}
 
 	if p == 0 {
 		return e.beep()
 	}
 
-	e.Buffer[p-1], e.Buffer[p] = e.Buffer[p], e.Buffer[p-1]
+	a, b := graphemeStart(e.Buffer, p), graphemeEnd(e.Buffer, p)
+	copy(e.Buffer[a:b], append(slices.Clone(e.Buffer[p:b]), e.Buffer[a:p]...))
 
 	if e.Cur < len(e.Buffer) {
-		e.Cur++
+		e.Cur = b
 	}
 
 	return e.refreshLine()
@@ -335,7 +869,7 @@ func (e *Terminal) editMoveLeft() error {
 		return e.beep()
 	}
 
-	e.Cur--
+	e.Cur = graphemeStart(e.Buffer, e.Cur)
 
 	return e.refreshLine()
 }
@@ -345,7 +879,7 @@ func (e *Terminal) editMoveRight() error {
 		return e.beep()
 	}
 
-	e.Cur++
+	e.Cur = graphemeEnd(e.Buffer, e.Cur)
 
 	return e.refreshLine()
 }
@@ -357,6 +891,7 @@ func (e *Terminal) editHistoryPrev() error {
 	}
 	e.Buffer = []rune(e.History.Get())
 	e.Cur = len(e.Buffer)
+	e.historyUsed = true
 	return e.refreshLine()
 }
 
@@ -366,10 +901,87 @@ func (e *Terminal) editHistoryNext() error {
 	}
 	e.Buffer = []rune(e.History.Get())
 	e.Cur = len(e.Buffer)
+	e.historyUsed = true
+	return e.refreshLine()
+}
+
+// editHistoryFirst goes to the oldest history entry, on PageUp.
+func (e *Terminal) editHistoryFirst() error {
+	e.History.Save(string(e.Buffer))
+	if err := e.History.First(); err != nil {
+		return e.beep()
+	}
+	e.Buffer = []rune(e.History.Get())
+	e.Cur = len(e.Buffer)
+	e.historyUsed = true
+	return e.refreshLine()
+}
+
+// editHistoryLast goes back to the line being edited, on PageDown.
+func (e *Terminal) editHistoryLast() error {
+	if err := e.History.Last(); err != nil {
+		return e.beep()
+	}
+	e.Buffer = []rune(e.History.Get())
+	e.Cur = len(e.Buffer)
+	e.historyUsed = true
+	return e.refreshLine()
+}
+
+// editAbort returns from history navigation to the line being edited, as it was before, and rings the bell.
+func (e *Terminal) editAbort() error {
+	if n := len(e.History.Lines); n > 0 && e.History.Pos != n-1 {
+		e.History.Pos = n - 1
+		e.Buffer = []rune(e.History.editing())
+		e.Cur = len(e.Buffer)
+		if err := e.refreshLine(); err != nil {
+			return err
+		}
+	}
+	return e.beep()
+}
+
+// editHistorySearch moves through history entries starting with the text before the cursor
+// when HistoryPrefixSearch is on, keeping the cursor in place.
+func (e *Terminal) editHistorySearch(dir int) error {
+	if !e.HistoryPrefixSearch || e.Cur == 0 {
+		if dir < 0 {
+			return e.editHistoryPrev()
+		}
+		return e.editHistoryNext()
+	}
+
+	prefix := string(e.Buffer[:e.Cur])
+	line := string(e.Buffer)
+	e.History.Save(line)
+
+	step := e.History.Prev
+	if dir > 0 {
+		step = e.History.Next
+	}
+
+	pos := e.History.Pos
+	for {
+		if err := step(); err != nil {
+			e.History.Pos = pos
+			return e.beep()
+		}
+		if e.History.Pos == len(e.History.Lines)-1 {
+			break // back at the line being edited
+		}
+		if l := e.History.Get(); l != line && strings.HasPrefix(l, prefix) {
+			break
+		}
+	}
+
+	e.Buffer = []rune(e.History.Get())
+	e.Cur = min(e.Cur, len(e.Buffer))
+	e.historyUsed = true
 	return e.refreshLine()
 }
 
 func (e *Terminal) editKillForward() error {
+	e.kill(e.Buffer[e.Cur:])
 	e.Buffer = e.Buffer[:e.Cur]
 	return e.refreshLine()
 }
@@ -392,29 +1004,66 @@ func (e *Terminal) editMoveEnd() error {
 	return e.refreshLine()
 }
 
-func (e *Terminal) editDeletePrevWord() error {
-	var w bool
-	var p int
-	for i := e.Cur - 1; i >= 0; i-- {
-		if e.Buffer[i] != ' ' {
-			w = true // found a word to delete
-			continue
-		}
+// editWordRight moves the cursor to the end of the next word, words being separated by WordBreakChars.
+func (e *Terminal) editWordRight() error {
+	if e.Cur == len(e.Buffer) {
+		return e.beep()
+	}
 
-		if !w {
-			continue
-		}
+	for e.Cur < len(e.Buffer) && e.wordBreak(e.Buffer[e.Cur]) {
+		e.Cur++
+	}
+	for e.Cur < len(e.Buffer) && !e.wordBreak(e.Buffer[e.Cur]) {
+		e.Cur++
+	}
+	return e.refreshLine()
+}
 
-		p = i + 1
-		break
+// editWordLeft moves the cursor to the start of the previous word.
+func (e *Terminal) editWordLeft() error {
+	if e.Cur == 0 {
+		return e.beep()
+	}
+
+	for e.Cur > 0 && e.wordBreak(e.Buffer[e.Cur-1]) {
+		e.Cur--
 	}
+	for e.Cur > 0 && !e.wordBreak(e.Buffer[e.Cur-1]) {
+		e.Cur--
+	}
+	return e.refreshLine()
+}
 
-	e.Buffer = e.Buffer[:p]
+// editDeletePrevWord kills from the start of the whitespace delimited word before the cursor, on Ctrl-W.
+// Unlike editBackwardKillWord it takes "/tmp/x" as a whole.
+func (e *Terminal) editDeletePrevWord() error {
+	p := e.Cur
+	for p > 0 && unicode.IsSpace(e.Buffer[p-1]) {
+		p--
+	}
+	for p > 0 && !unicode.IsSpace(e.Buffer[p-1]) {
+		p--
+	}
+
+	e.kill(e.Buffer[p:e.Cur])
+	e.Buffer = slices.Delete(e.Buffer, p, e.Cur)
 	e.Cur = p
 	return e.refreshLine()
 }
 
 func (e *Terminal) editInsert(r rune) error {
+	if e.Overwrite && e.Cur < len(e.Buffer) {
+		e.Buffer = slices.Replace(e.Buffer, e.Cur, graphemeEnd(e.Buffer, e.Cur), r)
+		e.Cur++
+		return e.refreshLine()
+	}
+	if ok, err := e.autoPair(r); ok {
+		return err
+	}
+	if e.room(1) == 0 {
+		return e.beep()
+	}
+
 	// Insert https://github.com/golang/go/wiki/SliceTricks
 	e.Buffer = append(e.Buffer, 0)
 	copy(e.Buffer[e.Cur+1:], e.Buffer[e.Cur:])
@@ -424,39 +1073,133 @@ func (e *Terminal) editInsert(r rune) error {
 	return e.refreshLine()
 }
 
+func (e *Terminal) editInsertRunes(rs []rune) error {
+	n := e.room(len(rs))
+	e.Buffer = slices.Insert(e.Buffer, e.Cur, rs[:n]...)
+	e.Cur += n
+	if err := e.refreshLine(); err != nil || n == len(rs) {
+		return err
+	}
+	return e.beep()
+}
+
+// editQuotedInsert inserts the next key as typed, even if it's bound, e.g. '?' bound to the help.
+func (e *Terminal) editQuotedInsert() error {
+	k, err := e.readKey()
+	if err != nil {
+		return err
+	}
+	switch {
+	case k.Code == KeyRune && k.Mod == 0:
+		return e.editInsertRunes([]rune{k.Rune})
+	case k == Key{Code: KeyTab}:
+		return e.editInsertRunes([]rune{tab})
+	}
+	return e.beep()
+}
+
+// room returns how many of n more runes fit into Buffer under MaxLineLen.
+func (e *Terminal) room(n int) int {
+	if e.MaxLineLen <= 0 {
+		return n
+	}
+	return max(0, min(n, e.MaxLineLen-len(e.Buffer)))
+}
+
 //
 
+// wordBounds returns the rune offsets of the word under the cursor, delimited by WordBreakChars or DefaultWordBreakChars.
+// A break escaped by a backslash, as in "my\ file", belongs to the word.
+func (e *Terminal) wordBounds() (start, end int) {
+	escaped := func(i int) bool { return i > 0 && e.Buffer[i-1] == '\\' }
+
+	start, end = e.Cur, e.Cur
+	for start > 0 && (!e.completionBreak(e.Buffer[start-1]) || escaped(start-1)) {
+		start--
+	}
+	for end < len(e.Buffer) && (!e.completionBreak(e.Buffer[end]) || escaped(end)) {
+		end++
+	}
+	return start, end
+}
+
+// Candidate is a completion suggestion which replaces the runes [ReplaceFrom, ReplaceTo) of the line.
+type Candidate struct {
+	Text        string
+	ReplaceFrom int
+	ReplaceTo   int
+	Score       int // OPTIONAL; Ranks the candidate for CompleteByScore, higher first.
+}
+
+// candidates returns opts as Candidates replacing the same runes.
+func candidates(opts []string, from, to int) []Candidate {
+	cs := make([]Candidate, len(opts))
+	for i, o := range opts {
+		cs[i] = Candidate{Text: o, ReplaceFrom: from, ReplaceTo: to}
+	}
+	return cs
+}
+
 func (e *Terminal) completeLine() error {
-	if e.Complete == nil {
+	start := time.Now()
+	var cs []Candidate
+	switch {
+	case e.CompleteContext != nil:
+		return e.completeAsync()
+	case e.CompleteRange != nil:
+		cs = e.CompleteRange(string(e.Buffer), e.Cur)
+	case e.CompleteWord != nil:
+		start, end := e.wordBounds()
+		cs = candidates(e.CompleteWord(string(e.Buffer), start, end), start, end)
+	case e.Complete != nil:
+		cs = candidates(e.Complete(string(e.Buffer)), 0, len(e.Buffer))
+	default:
 		return e.editInsert(tab)
 	}
+	if e.Metrics != nil {
+		e.Metrics.completed(start)
+	}
+	return e.applyCompletion(cs, true)
+}
 
-	var (
-		opts     = e.Complete(string(e.Buffer))
-		opts_len = len(opts)
-	)
+// applyCompletion puts the only candidate in place of the runes it replaces, the cursor after it,
+// or lists all of them; a listing taller than the screen is paged when page is set.
+func (e *Terminal) applyCompletion(cs []Candidate, page bool) error {
+	for i := range cs {
+		cs[i].ReplaceFrom = min(max(cs[i].ReplaceFrom, 0), len(e.Buffer))
+		cs[i].ReplaceTo = min(max(cs[i].ReplaceTo, cs[i].ReplaceFrom), len(e.Buffer))
+	}
+	cs = e.normalizeCandidates(cs)
+
+	var cells []string
+	if e.FuzzyComplete {
+		cs, cells = e.fuzzyCandidates(cs)
+	} else {
+		for _, c := range cs {
+			cells = append(cells, c.Text)
+		}
+	}
+
+	opts_len := len(cs)
 	switch opts_len {
 	case 0:
 		return e.beep()
 	case 1:
-		e.Buffer = []rune(opts[0])
-		e.Cur = len(e.Buffer)
-		return e.refreshLine()
+		return e.applyCandidate(cs[0])
 	}
-	// fmt.Fprintf(e.Out, "\n\r    %s\n", strings.Join(opts, "   ")); e.Out.Flush()
-	// const size = 3
-	// var tabl [][]string
-	// for i := 0; i < opts_len; i += size {
-	// tabl = append(tabl, opts[i:min(i+size, opts_len)])
-	// }
-
-	tw := new(tabwriter.Writer)
-	tw.Init(e.Out, 0, 0, 4, ' ', 0)
-	for chunk := range slices.Chunk(opts, 3) {
-		fmt.Fprintf(tw, "\n\r    %s\t", strings.Join(chunk, "\t"))
+	if e.CompleteMenu != nil {
+		return e.completeMenu(cs)
+	}
+	ew := &errWriter{w: e.Out}
+	rows := e.layoutColumns(cells, len("    "), 4)
+	if !page {
+		e.writeColumns(ew, "    ", rows, 4)
+	} else if err := e.pageColumns(ew, "    ", rows, 4, opts_len); err != nil {
+		return err
+	}
+	if ew.err != nil {
+		return ew.err
 	}
-	fmt.Fprintln(tw)
-	tw.Flush()
 
 	return e.refreshLine()
 	/*
@@ -496,26 +1239,44 @@ func (e *Terminal) completeLine() error {
 	// */
 }
 
+// applyCandidate puts c in place of the runes it replaces and the cursor after it.
+func (e *Terminal) applyCandidate(c Candidate) error {
+	if grow := len([]rune(c.Text)) - (c.ReplaceTo - c.ReplaceFrom); e.room(grow) < grow {
+		return e.beep()
+	}
+	e.Buffer = slices.Replace(e.Buffer, c.ReplaceFrom, c.ReplaceTo, []rune(c.Text)...)
+	e.Cur = c.ReplaceFrom + len([]rune(c.Text))
+	return e.refreshLine()
+}
+
+// printHelp lists the rows of Help, on F1 or Alt-h. Bind(Key{Rune: '?'}, "help") brings back
+// the help on '?', which can then be typed with Ctrl-V.
 func (e *Terminal) printHelp() error {
 	if e.Help == nil {
-		return e.editInsert('?')
+		return e.beep()
 	}
 
-	var (
-		dict = e.Help(string(e.Buffer))
-		tw   = new(tabwriter.Writer)
-	)
-	tw.Init(e.Out, 0, 0, 3, ' ', 0)
-	for _, v := range dict {
-		fmt.Fprintf(tw, "\n\r  %s\t%s\t", v[0], v[1])
+	var rows [][]string
+	for _, v := range e.Help(string(e.Buffer)) {
+		rows = append(rows, v[:])
+	}
+
+	ew := &errWriter{w: e.Out}
+	e.writeColumns(ew, "  ", rows, 3)
+	if ew.err != nil {
+		return ew.err
 	}
-	fmt.Fprintln(tw)
-	tw.Flush() // e.Out.Flush()
 
 	return e.refreshLine()
 }
 
 func (e *Terminal) hint() string {
+	if e.completion != nil {
+		return pendingHint
+	}
+	if e.HintContext != nil {
+		return e.asyncHint()
+	}
 	if e.Hint == nil {
 		return ""
 	}
@@ -545,138 +1306,252 @@ func (e *Terminal) refreshLine() error {
 		cols, rows int
 	}
 
-	hintStr := e.hint()
-
-	if e.WidthChar == nil {
-		e.WidthChar = defaultWidth
+	if e.suspended || e.deferRefresh() {
+		return nil
+	}
+	e.emit(EventRefresh)
+	if e.silent {
+		return e.refreshSilent()
+	}
+	if e.Metrics != nil {
+		e.Metrics.refreshes.Add(1)
 	}
 
-	//
-
-	// var pw int
-	// for _, r := range e.Prompt {
-	// 	pw += e.WidthChar(r)
-	// }
-	pw := visualWidth([]rune(e.Prompt))
+	hintStr := e.styleHint(e.hint())
 
-	var bw, cw, ocw int
-	for i, r := range e.Buffer {
-		if i < e.Cur {
-			cw += e.WidthChar(r)
-		}
-		if i < e.OldCur {
-			ocw += e.WidthChar(r)
-		}
-		bw += e.WidthChar(r)
+	if e.ContPrompt != "" || e.AlignContPrompt || !e.autoWrap() {
+		return e.refreshRows(hintStr)
 	}
 
-	var hw int
-	for _, r := range hintStr {
-		hw += e.WidthChar(r)
-	}
+	//
 
-	ep := pos{
-		// cols: (pw + bw + hw) % e.Cols,
-		rows: (pw + bw + hw) / e.Cols,
-	}
+	pw := e.promptWidth()
+
+	var (
+		bw = e.runesWidth(e.Buffer)
+		cw = e.runesWidth(e.Buffer[:e.Cur])
+		hw = e.width(hintStr)
+	)
 
 	cp := pos{
 		cols: (pw + cw) % e.Cols,
 		rows: (pw + cw) / e.Cols,
 	}
 
-	ocp := pos{
-		// cols: (pw + ocw) % e.Cols,
-		rows: (pw + ocw) / e.Cols,
+	w := pw + bw + hw
+	line := append(slices.Clone(e.Buffer), []rune(hintStr)...)
+	if e.DiffRefresh && e.drawn != nil && e.drawn.prompt == e.Prompt && e.MaxRows == 0 && w < e.Cols && !e.styled() {
+		e.OldCur = e.Cur
+		e.curRow = 0
+		return e.refreshDiff(pw, line, cp.cols)
 	}
 
 	ew := &errWriter{w: e.Out}
 
-	oldRows := e.MaxRows
-	if ep.rows > e.MaxRows {
-		e.MaxRows = ep.rows
-	}
-
 	// go to the bottom of editor region
-	if oldRows-ocp.rows > 0 {
-		ew.writeString(fmt.Sprintf("\x1b[%dB", oldRows-ocp.rows))
+	oldRows := e.MaxRows
+	if oldRows-e.curRow > 0 {
+		ew.writeString(e.caps().down(oldRows - e.curRow))
 	}
 
-	for i := 1; i < oldRows; i++ {
-		ew.writeString("\x1b[2K") // kill line
-		ew.writeString("\x1b[1A") // go up
+	for i := 0; i < oldRows; i++ {
+		ew.writeString(e.caps().ClearLine) // kill line
+		ew.writeString(e.caps().up(1))     // go up
 	}
 
 	ew.writeString("\r")
-	ew.writeString(e.Prompt)
-	ew.writeString(string(e.Buffer))
+	head := e.writePrompt(ew)
+	ew.writeString(e.styledString(e.Buffer, 0))
 	ew.writeString(hintStr)
-	ew.writeString("\x1b[0K")
+
+	row := w / e.Cols
+	if w > 0 && w%e.Cols == 0 {
+		// At the right edge the cursor stays on the last column,
+		// erasing from there would take the last character away.
+		row--
+	} else {
+		ew.writeString(e.caps().ClearEOL)
+	}
 
 	// If we are at the right edge,
 	// move cursor to the beginning of next line.
 	if e.Cur == len(e.Buffer) && cp.cols == 0 {
 		ew.writeString("\n\r")
-		cp.rows++
-		ep.rows++
-		if ep.rows > e.MaxRows {
-			e.MaxRows = ep.rows
-		}
+		row++
+	}
+	if head+row > e.MaxRows {
+		e.MaxRows = head + row
 	}
 
 	// Go up till we reach the expected position.
-	if ep.rows-cp.rows > 0 {
-		ew.writeString(fmt.Sprintf("\x1b[%dA", ep.rows-cp.rows))
+	if row-cp.rows > 0 {
+		ew.writeString(e.caps().up(row - cp.rows))
 	}
 
 	ew.writeString("\r")
 	if cp.cols > 0 {
-		ew.writeString(fmt.Sprintf("\x1b[%dC", cp.cols))
+		ew.writeString(e.caps().right(cp.cols))
 	}
 
 	ew.flush()
 
 	e.OldCur = e.Cur
+	e.curRow = head + cp.rows
+
+	e.drawn = nil
+	if e.DiffRefresh && e.MaxRows == 0 && w < e.Cols && !e.styled() {
+		e.drawn = &drawnLine{prompt: e.Prompt, line: line, col: cp.cols}
+	}
 
 	return ew.err
 }
-func defaultWidth(r rune) int {
-	if r == tab {
-		return 4
+
+// refreshRows breaks the input into rows itself and starts every row after the first with the continuation prompt.
+func (e *Terminal) refreshRows(hintStr string) error {
+	e.drawn = nil
+	cont := e.contPrompt()
+	pw := e.promptWidth()
+	cw := e.width(cont)
+
+	var (
+		rows           = [][]rune{nil}
+		starts         = []int{0} // offsets of the rows in Buffer followed by the hint.
+		col            = pw
+		curRow, curCol int
+	)
+	newRow := func() {
+		starts = append(starts, starts[len(rows)-1]+len(rows[len(rows)-1]))
+		rows = append(rows, nil)
+		col = cw
 	}
-	return 1
-}
-func visualWidth(runes []rune) (length int) {
-	inEscSeq := false
-	for _, r := range runes {
-		switch {
-		case inEscSeq:
-			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
-				inEscSeq = false
+	place := func(g []rune) {
+		w := e.runesWidth(g)
+		if col+w > e.Cols && col > cw {
+			newRow()
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], g...)
+		col += w
+	}
+
+	for i, end := 0, 0; i < len(e.Buffer); i = end {
+		end = graphemeEnd(e.Buffer, i)
+		if i <= e.Cur && e.Cur < end {
+			if col+e.runesWidth(e.Buffer[i:end]) > e.Cols && col > cw {
+				newRow()
 			}
-		case r == '\x1b':
-			inEscSeq = true
-		default:
-			length++
+			curRow, curCol = len(rows)-1, col
+		}
+		place(e.Buffer[i:end])
+	}
+	if e.Cur == len(e.Buffer) {
+		if col >= e.Cols {
+			newRow()
+		}
+		curRow, curCol = len(rows)-1, col
+	}
+	hint := []rune(hintStr)
+	for i, end := 0, 0; i < len(hint); i = end {
+		if hint[i] == '\x1b' { // styling of the hint takes no room
+			end = escapeEnd(hint, i)
+			rows[len(rows)-1] = append(rows[len(rows)-1], hint[i:end]...)
+			continue
+		}
+		end = graphemeEnd(hint, i)
+		place(hint[i:end])
+	}
+
+	ew := &errWriter{w: e.Out}
+
+	// go to the top of editor region
+	if e.curRow > 0 {
+		ew.writeString(e.caps().up(e.curRow))
+	}
+
+	var head int
+	for i, row := range rows {
+		if i == 0 {
+			ew.writeString("\r")
+			head = e.writePrompt(ew)
+		} else {
+			ew.writeString("\r\n")
+			ew.writeString(cont)
+		}
+		ew.writeString(e.styledString(row, starts[i]))
+		start := cw
+		if i == 0 {
+			start = pw
+		}
+		if start+e.width(string(row)) < e.Cols {
+			// after a full row the cursor stays on its last character, erasing would take it away
+			ew.writeString(e.caps().ClearEOL)
 		}
 	}
-	return
+
+	// kill rows left over from a taller edit
+	last := head + len(rows) - 1
+	for ; last < e.MaxRows; last++ {
+		ew.writeString("\r\n" + e.caps().ClearLine)
+	}
+
+	if last-head-curRow > 0 {
+		ew.writeString(e.caps().up(last - head - curRow))
+	}
+	ew.writeString("\r")
+	if curCol > 0 {
+		ew.writeString(e.caps().right(curCol))
+	}
+
+	ew.flush()
+
+	e.MaxRows = head + len(rows) - 1
+	e.curRow = head + curRow
+	e.OldCur = e.Cur
+
+	return ew.err
+}
+
+func (e *Terminal) contPrompt() string {
+	if !e.AlignContPrompt {
+		return e.ContPrompt
+	}
+	pad := e.promptWidth() - e.width(e.ContPrompt)
+	if pad <= 0 {
+		return e.ContPrompt
+	}
+	return strings.Repeat(" ", pad) + e.ContPrompt
 }
 
 //
 
 func (e *Terminal) clearScreen() error {
-	n, err := e.Out.WriteString("\x1b[H\x1b[2J")
+	e.drawn = nil
+	seq := e.caps().ClearScreen
+	n, err := e.Out.WriteString(seq)
 	if err != nil {
 		return err
 	}
-	if n != 7 {
+	if n != len(seq) {
 		return errors.New("failed to clear screen")
 	}
-	return nil
+	e.statusShown = false
+	ew := &errWriter{w: e.Out}
+	e.writeStatus(ew)
+	return ew.err
+}
+
+// writeSeq writes a control sequence to the terminal right away.
+func (e *Terminal) writeSeq(seq string) error {
+	ew := errWriter{w: e.Out}
+	ew.writeString(seq)
+	ew.flush()
+	return ew.err
 }
 
 func (e *Terminal) beep() error {
+	if e.silent {
+		return nil // would tell e.g. that the line is empty
+	}
+	e.emit(EventBell)
 	if _, err := e.Out.WriteString("\a"); err != nil {
 		return err
 	}
@@ -713,49 +1588,3 @@ func (ew *errWriter) flush() {
 	}
 	ew.err = ew.w.Flush()
 }
-
-//
-
-type History struct {
-	Lines []string
-	Pos   int
-}
-
-func (h *History) Add(l string) {
-	if len(h.Lines) == 0 {
-		h.Lines = []string{""}
-	}
-	h.Lines[len(h.Lines)-1] = l
-	h.Lines = append(h.Lines, "")
-	h.Pos = len(h.Lines) - 1
-}
-
-func (h *History) Next() error {
-	if h.Pos >= len(h.Lines)-1 {
-		return errors.New("end of history")
-	}
-	h.Pos++
-	return nil
-}
-
-func (h *History) Prev() error {
-	if h.Pos <= 0 {
-		return errors.New("beginning of history")
-	}
-	h.Pos--
-	return nil
-}
-
-func (h *History) Get() string {
-	return h.Lines[h.Pos]
-}
-
-func (h *History) Save(l string) {
-	if len(h.Lines) == 0 {
-		h.Lines = []string{""}
-	}
-	if h.Pos != len(h.Lines)-1 {
-		return
-	}
-	h.Lines[len(h.Lines)-1] = l
-}
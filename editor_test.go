@@ -3,9 +3,17 @@ package linenoisy
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/Joker/linenoisy/vtest"
 )
 
 func TestEditor_LineEnter(t *testing.T) {
@@ -58,8 +66,8 @@ func TestEditor_LineCtrlC(t *testing.T) {
 	}
 
 	l, err := e.LineEditor()
-	if err == nil {
-		t.Error("err expected")
+	if !errors.Is(err, ErrInterrupted) {
+		t.Errorf("expected ErrInterrupted got %v", err)
 	}
 	if l != "foo b" {
 		t.Errorf(`expected "foo b" got %#v`, l)
@@ -550,6 +558,106 @@ func TestEditor_LineEscSquareBracketAEscSquareBracketB(t *testing.T) {
 	}
 }
 
+func TestEditor_LineHistoryPrefixSearch(t *testing.T) {
+	in := bytes.NewBuffer([]byte("git\x1b[A\x1b[A\x1b[A\x1b[B\x1b[B\x0d"))
+	out := &checkedWriter{
+		expectations: []string{
+			"\r> \x1b[0K\r\x1b[2C",
+			"\r> g\x1b[0K\r\x1b[3C",
+			"\r> gi\x1b[0K\r\x1b[4C",
+			"\r> git\x1b[0K\r\x1b[5C",
+			"\r> git push\x1b[0K\r\x1b[5C",
+			"\r> git pull\x1b[0K\r\x1b[5C",
+			"\a",
+			"\r> git push\x1b[0K\r\x1b[5C",
+			"\r> git\x1b[0K\r\x1b[5C",
+		},
+	}
+
+	e := &Terminal{
+		Inp:                 bufio.NewReader(in),
+		Out:                 bufio.NewWriter(out),
+		Prompt:              "> ",
+		HistoryPrefixSearch: true,
+	}
+	e.History.Add("git pull")
+	e.History.Add("ls")
+	e.History.Add("git push")
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "git" {
+		t.Errorf(`expected "git" got %#v`, l)
+	}
+}
+
+func TestEditor_LineHistoryPageKeys(t *testing.T) {
+	in := bytes.NewBuffer([]byte("x\x1b[5~\x1b[5~\x1b[6~\x1b[6~\x0d"))
+	out := &checkedWriter{
+		expectations: []string{
+			"\r> \x1b[0K\r\x1b[2C",
+			"\r> x\x1b[0K\r\x1b[3C",
+			"\r> first\x1b[0K\r\x1b[7C",
+			"\a",
+			"\r> x\x1b[0K\r\x1b[3C",
+			"\a",
+		},
+	}
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+	}
+	e.History.Add("first")
+	e.History.Add("second")
+	e.History.Add("third")
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "x" {
+		t.Errorf(`expected "x" got %#v`, l)
+	}
+}
+
+func TestEditor_LineHistoryExpansion(t *testing.T) {
+	in := bytes.NewBuffer([]byte("!x\x0d\x7f\x7f!!\x0d\x0d"))
+	out := &checkedWriter{
+		expectations: []string{
+			"\r> \x1b[0K\r\x1b[2C",
+			"\r> !\x1b[0K\r\x1b[3C",
+			"\r> !x\x1b[0K\r\x1b[4C",
+			"\a",
+			"\r> !\x1b[0K\r\x1b[3C",
+			"\r> \x1b[0K\r\x1b[2C",
+			"\r> !\x1b[0K\r\x1b[3C",
+			"\r> !!\x1b[0K\r\x1b[4C",
+			"\r> ls\x1b[0K\r\x1b[4C",
+		},
+	}
+
+	e := &Terminal{
+		Inp:              bufio.NewReader(in),
+		Out:              bufio.NewWriter(out),
+		Prompt:           "> ",
+		HistoryExpansion: true,
+		HistoryVerify:    true,
+	}
+	e.History.Add("ls")
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "ls" {
+		t.Errorf(`expected "ls" got %#v`, l)
+	}
+}
+
 func TestEditor_LineEscSquareBracketCEscSquareBracketD(t *testing.T) {
 	in := bytes.NewBuffer([]byte("foo bar\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x0d"))
 	out := &checkedWriter{
@@ -760,6 +868,35 @@ func TestEditor_LineTabSomeCompletions(t *testing.T) {
 	}
 }
 
+func TestEditor_LineHelpWideChars(t *testing.T) {
+	in := bytes.NewBuffer([]byte("\x1bh\x0d"))
+	out := &checkedWriter{
+		expectations: []string{
+			"\r> \x1b[0K\r\x1b[2C",
+			"\n\r  日本   Japan   \n\r  jp     Japan   \n\r> \x1b[0K\r\x1b[2C",
+		},
+	}
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+		Help: func(string) [][2]string {
+			return [][2]string{{"日本", "Japan"}, {"jp", "Japan"}}
+		},
+		WidthChar: func(r rune) int {
+			if r > 0x2e80 {
+				return 2
+			}
+			return 1
+		},
+	}
+
+	if _, err := e.LineEditor(); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestEditor_LineHint(t *testing.T) {
 	in := bytes.NewBuffer([]byte("foo bar\x0d"))
 	out := &checkedWriter{
@@ -768,7 +905,7 @@ func TestEditor_LineHint(t *testing.T) {
 			"\r> f\x1b[0K\r\x1b[3C",
 			"\r> fo\x1b[0K\r\x1b[4C",
 			"\r> foo\x1b[0K\r\x1b[5C",
-			"\r> foo bar\x1b[0K\r\x1b[6C",
+			"\r> foo \x1b[2mbar\x1b[0m\x1b[0K\r\x1b[6C",
 			"\r> foo b\x1b[0K\r\x1b[7C",
 			"\r> foo ba\x1b[0K\r\x1b[8C",
 			"\r> foo bar\x1b[0K\r\x1b[9C",
@@ -796,6 +933,173 @@ func TestEditor_LineHint(t *testing.T) {
 	}
 }
 
+func TestEditor_LineHintStyle(t *testing.T) {
+	for _, tt := range []struct {
+		style []byte
+		want  string
+	}{
+		{Cyan, "\r> foo \x1b[36mbar\x1b[0m\x1b[0K\r\x1b[6C"},
+		{[]byte{}, "\r> foo bar\x1b[0K\r\x1b[6C"},
+	} {
+		var out bytes.Buffer
+		e := &Terminal{
+			Out:       bufio.NewWriter(&out),
+			Prompt:    "> ",
+			Buffer:    []rune("foo "),
+			Cur:       4,
+			Cols:      80,
+			HintStyle: tt.style,
+			Hint:      func(string) string { return "bar" },
+		}
+		if err := e.refreshLine(); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("expected %q got %q", tt.want, out.String())
+		}
+	}
+}
+
+func TestEditor_LineNoAutoWrap(t *testing.T) {
+	s := vtest.New(10, 4)
+	s.Write([]byte("\x1b[?7l"))
+	e := &Terminal{
+		Inp:        bufio.NewReader(bytes.NewBufferString("abcdefghijklmnop\x02\x02\x02\x02\x02\x02\x02X\x0d")),
+		Out:        bufio.NewWriter(s),
+		Prompt:     "> ",
+		Cols:       10,
+		Rows:       4,
+		NoAutoWrap: true,
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "abcdefghiXjklmnop" {
+		t.Errorf(`expected "abcdefghiXjklmnop" got %#v`, l)
+	}
+	if want := []string{"> abcdefgh", "iXjklmnop", "", ""}; !slices.Equal(s.Lines(), want) {
+		t.Errorf("expected %q got %q", want, s.Lines())
+	}
+}
+
+func TestEditor_ContPrompt(t *testing.T) {
+	out := &checkedWriter{
+		expectations: []string{
+			"\r> abcdefgh\r\n .ij\x1b[0K\r\x1b[4C",
+			"\x1b[1A\r> abcdefgh\r\n .ij\x1b[0K\x1b[1A\r\x1b[3C",
+			"\r> a\x1b[0K\r\n\x1b[2K\x1b[1A\r\x1b[3C",
+		},
+	}
+
+	e := &Terminal{
+		Out:             bufio.NewWriter(out),
+		Prompt:          "> ",
+		ContPrompt:      ".",
+		AlignContPrompt: true,
+		Cols:            10,
+		Buffer:          []rune("abcdefghij"),
+		Cur:             10,
+	}
+
+	if err := e.refreshLine(); err != nil {
+		t.Fatal(err)
+	}
+
+	e.Cur = 1
+	if err := e.refreshLine(); err != nil {
+		t.Fatal(err)
+	}
+
+	e.Buffer = e.Buffer[:1]
+	if err := e.refreshLine(); err != nil {
+		t.Fatal(err)
+	}
+	if e.MaxRows != 0 {
+		t.Errorf("expected 0 got %d", e.MaxRows)
+	}
+}
+
+func TestEditor_LineNonInteractive(t *testing.T) {
+	in := bytes.NewBuffer([]byte("foo bar\r\n\x01baz"))
+	out := &checkedWriter{}
+
+	interactive := false
+	e := &Terminal{
+		Inp:         bufio.NewReader(in),
+		Out:         bufio.NewWriter(out),
+		Prompt:      "> ",
+		Interactive: &interactive,
+	}
+
+	for _, want := range []string{"foo bar", "\x01baz"} {
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Error(err)
+		}
+		if l != want {
+			t.Errorf(`expected %#v got %#v`, want, l)
+		}
+	}
+
+	if _, err := e.LineEditor(); err != io.EOF {
+		t.Errorf("expected io.EOF got %v", err)
+	}
+}
+
+func TestEditor_LinePipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	go func() {
+		w.Write([]byte("foo\n"))
+		w.Close()
+	}()
+
+	e := NewTerminal(r, "> ")
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "foo" {
+		t.Errorf(`expected "foo" got %#v`, l)
+	}
+}
+
+func TestEditor_SuspendResume(t *testing.T) {
+	out := &checkedWriter{
+		expectations: []string{
+			"\x1b[1A\r\x1b[0J",
+			"\r> foo bar\x1b[0K\r\x1b[5C",
+		},
+	}
+
+	e := &Terminal{
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+		Buffer: []rune("foo bar"),
+		Cur:    3,
+		curRow: 1,
+	}
+
+	if err := e.Suspend(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.refreshLine(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if e.curRow != 0 {
+		t.Errorf("expected 0 got %d", e.curRow)
+	}
+}
+
 func TestEditor_Adjust(t *testing.T) {
 	in := bytes.NewBuffer([]byte("\x1b[100;200R"))
 	out := &checkedWriter{
@@ -822,6 +1126,28 @@ func TestEditor_Adjust(t *testing.T) {
 	}
 }
 
+func TestEditor_AdjustAfterEscTimeout(t *testing.T) {
+	pr, pw := io.Pipe()
+	e := &Terminal{
+		Inp:    bufio.NewReader(pr),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+	}
+
+	// a lone ESC timed out, the wait for the next input goes on in the background
+	if e.waitInput(time.Millisecond) {
+		t.Fatal("expected no input")
+	}
+	go pw.Write([]byte("\x1b[100;200R"))
+
+	if err := e.Adjust(); err != nil {
+		t.Fatal(err)
+	}
+	if e.Rows != 100 || e.Cols != 200 {
+		t.Errorf("expected 200x100 got %dx%d", e.Cols, e.Rows)
+	}
+}
+
 func TestEditor_WriteOut(t *testing.T) {
 	in := bytes.NewBuffer(nil)
 	out := &checkedWriter{
@@ -847,6 +1173,268 @@ func TestEditor_WriteOut(t *testing.T) {
 	}
 }
 
+func TestEditor_WriteOutConcurrent(t *testing.T) {
+	pr, pw := io.Pipe()
+	out := &syncBuffer{}
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(pr),
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 50 {
+			if _, err := e.WriteOut([]byte(fmt.Sprintf("log %d\n", i))); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() {
+		for range 50 {
+			pw.Write([]byte("a"))
+		}
+		<-done
+		pw.Write([]byte("\x0d"))
+	}()
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != strings.Repeat("a", 50) {
+		t.Errorf("expected 50 a got %#v", l)
+	}
+	if !strings.Contains(out.String(), "log 49\r\n") {
+		t.Errorf("missing output %q", out.String())
+	}
+}
+
+func TestEditor_WriteOutConcurrentScreen(t *testing.T) {
+	pr, pw := io.Pipe()
+	s := vtest.New(20, 12)
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(pr),
+		Out:    bufio.NewWriter(s), // written under the edit lock only
+		Prompt: "> ",
+		Cols:   20,
+		Rows:   12,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 30 {
+			if _, err := e.WriteOut([]byte(fmt.Sprintf("log %d", i))); err != nil {
+				t.Error(err)
+				return
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+	go func() {
+		for range 30 {
+			pw.Write([]byte("ab\x7f")) // a wrapping line, redrawn on every key
+			time.Sleep(100 * time.Microsecond)
+		}
+		<-done
+		pw.Write([]byte("\x0d"))
+	}()
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != strings.Repeat("a", 30) {
+		t.Errorf("expected 30 a got %#v", l)
+	}
+
+	// the logs in order, then the two rows of the line, nothing of the redraws in between
+	want := []string{"log 20", "log 21", "log 22", "log 23", "log 24", "log 25", "log 26", "log 27", "log 28", "log 29",
+		"> " + strings.Repeat("a", 18), strings.Repeat("a", 12)}
+	if got := s.Lines(); !slices.Equal(got, want) {
+		t.Errorf("expected %q got %q", want, got)
+	}
+}
+
+func TestEditor_WriteOutBurst(t *testing.T) {
+	out := &syncBuffer{}
+	e := &Terminal{
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+	}
+
+	e.edit.Lock() // LineEditor is busy
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if n, err := e.WriteOut([]byte(fmt.Sprintf("log %d", i))); err != nil || n != 5 {
+				t.Errorf("unexpected %d, %v", n, err)
+			}
+		}()
+	}
+	for {
+		e.outq.mu.Lock()
+		queued := e.outq.queued
+		e.outq.mu.Unlock()
+		if queued == 10 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	e.edit.Unlock()
+	wg.Wait()
+
+	got := out.String()
+	if n := strings.Count(got, "\r> "); n != 1 {
+		t.Errorf("expected a single redraw got %d in %q", n, got)
+	}
+	if n := strings.Count(got, "log "); n != 10 {
+		t.Errorf("expected 10 lines got %d in %q", n, got)
+	}
+}
+
+func TestEditor_WriteOutMultiRow(t *testing.T) {
+	out := &checkedWriter{
+		expectations: []string{
+			"\r> abcdefghijklmno\x1b[0K\r\x1b[7C",
+			"\x1b[1A\r\x1b[0Jlog\r\n",
+			"\r> abcdefghijklmno\x1b[0K\r\x1b[7C",
+			"\x1b[1A\r\x1b[0Jpartial\r\n",
+			"\r> abcdefghijklmno\x1b[0K\r\x1b[7C",
+		},
+	}
+
+	e := &Terminal{
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+		Cols:   10,
+		Buffer: []rune("abcdefghijklmno"),
+		Cur:    15,
+	}
+
+	if err := e.refreshLine(); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"log\n", "partial"} {
+		if _, err := e.WriteOut([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		if e.curRow != 1 || e.MaxRows != 1 || e.Cur != 15 {
+			t.Errorf("unexpected curRow %d MaxRows %d Cur %d", e.curRow, e.MaxRows, e.Cur)
+		}
+	}
+}
+
+func TestEditor_LineScreen(t *testing.T) {
+	s := vtest.New(10, 6)
+	e := &Terminal{
+		Inp:    bufio.NewReader(bytes.NewBufferString("abcdefghijklmnopqrstuvwxy" + strings.Repeat("\x02", 12) + "\x7f\x7f")),
+		Out:    bufio.NewWriter(s),
+		Prompt: "> ",
+		Cols:   10,
+		Rows:   6,
+	}
+
+	if _, err := e.LineEditor(); err != io.EOF {
+		t.Fatalf("expected EOF got %v", err)
+	}
+	if want := "> abcdefgh\nijknopqrst\nuvwxy"; s.String() != want {
+		t.Errorf("expected %q got %q", want, s.String())
+	}
+	if col, row := s.Cursor(); col != 3 || row != 1 {
+		t.Errorf("expected cursor 3,1 got %d,%d", col, row)
+	}
+}
+
+func TestEditor_LineScreenWide(t *testing.T) {
+	for _, tt := range []struct {
+		in       string
+		screen   string
+		col, row int
+	}{
+		{"日本語abcd\x02\x02", "> 日本語ab\ncd", 0, 1},
+		{"日本語abcd\x02\x02\x02", "> 日本語ab\ncd", 9, 0},
+		{"日本語abcd\x01", "> 日本語ab\ncd", 2, 0},
+	} {
+		s := vtest.New(10, 6)
+		e := &Terminal{
+			Inp:    bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:    bufio.NewWriter(s),
+			Prompt: "> ",
+			Cols:   10,
+			Rows:   6,
+		}
+
+		if _, err := e.LineEditor(); err != io.EOF {
+			t.Fatalf("expected EOF got %v", err)
+		}
+		if s.String() != tt.screen {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.screen, s.String())
+		}
+		if col, row := s.Cursor(); col != tt.col || row != tt.row {
+			t.Errorf("%q: expected cursor %d,%d got %d,%d", tt.in, tt.col, tt.row, col, row)
+		}
+	}
+}
+
+func TestEditor_WriteOutScreen(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		buffer     string
+		cur        int
+		contPrompt string
+	}{
+		{"one row", "abc", 1, ""},
+		{"cursor on the first row", "abcdefghijklmnopqrstu", 3, ""},
+		{"cursor on the last row", "abcdefghijklmnopqrstu", 21, ""},
+		{"exact width", "abcdefgh", 8, ""},
+		{"rows", "abcdefghijklmnopqrstu", 12, "."},
+		{"rows exact width", "abcdefghijklmnop", 16, "."},
+	} {
+		s := vtest.New(10, 6)
+		e := &Terminal{
+			Out:        bufio.NewWriter(s),
+			Prompt:     "> ",
+			ContPrompt: tt.contPrompt,
+			Cols:       10,
+			Rows:       6,
+			Buffer:     []rune(tt.buffer),
+			Cur:        tt.cur,
+		}
+		if err := e.refreshLine(); err != nil {
+			t.Fatal(err)
+		}
+		want := s.Lines()
+		col, row := s.Cursor()
+
+		for i := range 3 {
+			if _, err := e.WriteOut([]byte(fmt.Sprintf("log %d", i))); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got := s.Lines()
+		for i := range 3 {
+			if got[i] != fmt.Sprintf("log %d", i) {
+				t.Errorf("%s: expected log %d on row %d got %q", tt.name, i, i, got)
+			}
+		}
+		if !slices.Equal(got[3:], want[:len(want)-3]) || want[len(want)-3] != "" {
+			t.Errorf("%s: expected %q below the logs got %q", tt.name, want, got)
+		}
+		if c, r := s.Cursor(); c != col || r != row+3 {
+			t.Errorf("%s: expected cursor %d,%d got %d,%d", tt.name, col, row+3, c, r)
+		}
+	}
+}
+
 type checkedWriter struct {
 	expectations []string
 	pos          int
@@ -865,3 +1453,468 @@ func (c *checkedWriter) Write(p []byte) (int, error) {
 	c.pos++
 	return len(p), nil
 }
+
+func TestEditor_LineOverwrite(t *testing.T) {
+	in := bytes.NewBuffer([]byte("abcd\x01\x1b[2~xy\x1b[2~z\x0d"))
+
+	var modes []bool
+	e := &Terminal{
+		Inp:         bufio.NewReader(in),
+		Out:         bufio.NewWriter(&bytes.Buffer{}),
+		Prompt:      "> ",
+		ModeChanged: func(overwrite bool) { modes = append(modes, overwrite) },
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "xyzcd" {
+		t.Errorf(`expected "xyzcd" got %#v`, l)
+	}
+	if !slices.Equal(modes, []bool{true, false}) {
+		t.Errorf("expected [true false] got %v", modes)
+	}
+}
+
+func TestEditor_LineExternalEdit(t *testing.T) {
+	in := bytes.NewBuffer([]byte("foo\x18\x05!\x0d"))
+	out := &checkedWriter{
+		expectations: []string{
+			"\r> \x1b[0K\r\x1b[2C",
+			"\r> f\x1b[0K\r\x1b[3C",
+			"\r> fo\x1b[0K\r\x1b[4C",
+			"\r> foo\x1b[0K\r\x1b[5C",
+			"\r\x1b[0K",
+			"\r> foo bar\x1b[0K\r\x1b[9C",
+			"\r> foo bar!\x1b[0K\r\x1b[10C",
+		},
+	}
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+		ExternalEdit: func(current string) (string, error) {
+			if current != "foo" {
+				t.Errorf(`expected "foo" got %#v`, current)
+			}
+			return "foo\nbar\n", nil
+		},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "foo bar!" {
+		t.Errorf(`expected "foo bar!" got %#v`, l)
+	}
+}
+
+func TestEditor_LineCompleteWord(t *testing.T) {
+	in := bytes.NewBuffer([]byte("git ch main\x02\x02\x02\x02\x02\t\x0d"))
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+		CompleteWord: func(line string, start, end int) []string {
+			if word := string([]rune(line)[start:end]); word != "ch" {
+				t.Errorf(`expected "ch" got %#v`, word)
+			}
+			return []string{"checkout"}
+		},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "git checkout main" {
+		t.Errorf(`expected "git checkout main" got %#v`, l)
+	}
+	if e.Cur != 12 {
+		t.Errorf("expected cursor 12 got %d", e.Cur)
+	}
+}
+
+func TestEditor_WordBoundsEscaped(t *testing.T) {
+	e := &Terminal{Buffer: []rune(`cat my\ dir/x y`), Cur: 9}
+	if start, end := e.wordBounds(); start != 4 || end != 13 {
+		t.Errorf("expected 4,13 got %d,%d", start, end)
+	}
+}
+
+func TestEditor_LineCompleteRange(t *testing.T) {
+	in := bytes.NewBuffer([]byte("cd /usr/lo bin\x02\x02\x02\x02\t\x0d"))
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+		CompleteRange: func(line string, pos int) []Candidate {
+			if pos != 10 {
+				t.Errorf("expected cursor 10 got %d", pos)
+			}
+			return []Candidate{{Text: "local", ReplaceFrom: 8, ReplaceTo: 10}}
+		},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "cd /usr/local bin" {
+		t.Errorf(`expected "cd /usr/local bin" got %#v`, l)
+	}
+	if e.Cur != 13 {
+		t.Errorf("expected cursor 13 got %d", e.Cur)
+	}
+}
+
+func TestEditor_LineCtrlG(t *testing.T) {
+	in := bytes.NewBuffer([]byte("ba\x10\x10x\x07r\x0d"))
+
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp:     bufio.NewReader(in),
+		Out:     bufio.NewWriter(&out),
+		Prompt:  "> ",
+		History: History{Lines: []string{"foo", "baz", ""}, Pos: 2},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "bar" {
+		t.Errorf(`expected "bar" got %#v`, l)
+	}
+	if !strings.HasSuffix(out.String(), "\r> ba\x1b[0K\r\x1b[4C\a\r> bar\x1b[0K\r\x1b[5C") {
+		t.Errorf("expected the line restored and a bell got %q", out.String())
+	}
+}
+
+func TestEditor_LineTildeKeys(t *testing.T) {
+	in := bytes.NewBuffer([]byte("bc\x1b[1~a\x1b[4~d\x1b[7~\x1b[3~A\x1b[5~\x1b[6~\x0d"))
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+	}
+	e.History.Add("foo")
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "Abcd" {
+		t.Errorf(`expected "Abcd" got %#v`, l)
+	}
+}
+
+func TestEditor_LineWordMotion(t *testing.T) {
+	in := bytes.NewBuffer([]byte("git commit -m msg\x1b[1;5D\x1b[1;3DX\x1b[1;5C\x1b[1;5CY\x1bb\x1bbZ\x1bfW\x0d"))
+
+	e := &Terminal{
+		Inp:            bufio.NewReader(in),
+		Out:            bufio.NewWriter(&bytes.Buffer{}),
+		Prompt:         "> ",
+		WordBreakChars: " -",
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "git commit -ZXmW msgY" {
+		t.Errorf(`expected "git commit -ZXmW msgY" got %#v`, l)
+	}
+}
+
+func TestEditor_LineWordBreakChars(t *testing.T) {
+	tests := []struct {
+		chars, in, want string
+	}{
+		{"", "rm -rf /tmp/x\x1bb\x1bbX\x0d", "rm -rf /Xtmp/x"},
+		{"", "cd /tmp/x;ls\x1bb\x17\x0d", "cd ls"},
+		{"", "rm -rf /tmp/x\x01\x1bd\x1bd\x0d", " /tmp/x"},
+		{"", "cd /tmp/x\x1b\x7f\x0d", "cd /tmp/"},
+		{"", "cd /tmp/x\x17\x0d", "cd "},
+		{"", "foo.bar-baz\x1b\x7f\x1b\x7f\x0d", "foo."},
+		{" /", "cd /tmp/x\x1b\x7f\x1b\x7f\x0d", "cd /"},
+		{" /", "cd /tmp/x\x17\x0d", "cd "},
+		{"", "a=b c\x1b\x7f\x1b\x7f\x0d", "a="},
+	}
+	for _, tt := range tests {
+		e := &Terminal{
+			Inp:            bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:            bufio.NewWriter(&bytes.Buffer{}),
+			Prompt:         "> ",
+			WordBreakChars: tt.chars,
+		}
+
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Error(err)
+		}
+		if l != tt.want {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.want, l)
+		}
+	}
+}
+
+func TestEditor_LineMouseWheel(t *testing.T) {
+	in := bytes.NewBuffer([]byte("\x1b[<64;1;1M\x1b[<64;1;1M\x1b[<65;1;1M!\x0d"))
+
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(&out),
+		Prompt: "> ",
+		Mouse:  true,
+	}
+	e.History.Add("foo")
+	e.History.Add("bar")
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "bar!" {
+		t.Errorf(`expected "bar!" got %#v`, l)
+	}
+	if s := out.String(); !strings.HasPrefix(s, "\x1b[?1000h\x1b[?1006h") || !strings.HasSuffix(s, "\x1b[?1006l\x1b[?1000l") {
+		t.Errorf("expected mouse reporting on and off got %q", s)
+	}
+}
+
+func TestEditor_TransientPrompt(t *testing.T) {
+	s := vtest.New(10, 6)
+	e := &Terminal{
+		Inp:             bufio.NewReader(bytes.NewBufferString("abcdefghijkl\x01\x0d")),
+		Out:             bufio.NewWriter(s),
+		Prompt:          "user@host:~$ ",
+		Cols:            10,
+		Rows:            6,
+		Hint:            func(line string) string { return " <hint>" },
+		TransientPrompt: func(line string) string { return "$ " },
+	}
+
+	if _, err := e.LineEditor(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "$ abcdefgh\nijkl"; s.String() != want {
+		t.Errorf("expected %q got %q", want, s.String())
+	}
+	if col, row := s.Cursor(); col != 4 || row != 1 {
+		t.Errorf("expected cursor 4,1 got %d,%d", col, row)
+	}
+}
+
+func TestEditor_OnKey(t *testing.T) {
+	errQuit := errors.New("quit")
+	e := &Terminal{
+		Inp:    bufio.NewReader(bytes.NewBufferString("\x04ls\x14\r\x04\x11")),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+		OnKey: func(k Key) (bool, error) {
+			switch k {
+			case ctrl('d'), ctrl('t'): // no EOF, no transposition
+				return true, nil
+			case ctrl('q'):
+				return true, errQuit
+			}
+			return false, nil
+		},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "ls" {
+		t.Errorf(`expected "ls" got %#v`, l)
+	}
+
+	if _, err := e.LineEditor(); err != errQuit {
+		t.Errorf("expected %v got %v", errQuit, err)
+	}
+}
+
+func TestEditor_OnChange(t *testing.T) {
+	var changes []string
+	e := &Terminal{
+		Inp:    bufio.NewReader(bytes.NewBufferString("ab\x02\x02\x02\x7fc\r")),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+		OnChange: func(line string, pos int) {
+			changes = append(changes, fmt.Sprintf("%s:%d", line, pos))
+		},
+	}
+
+	if _, err := e.LineEditor(); err != nil {
+		t.Error(err)
+	}
+	// the third Ctrl-B and the Backspace at the beginning change nothing
+	if want := []string{"a:1", "ab:2", "ab:1", "ab:0", "cab:1"}; !slices.Equal(changes, want) {
+		t.Errorf("expected %q got %q", want, changes)
+	}
+}
+
+func TestEditor_YankLastArg(t *testing.T) {
+	for _, tt := range []struct {
+		in, line string
+		maxLen   int
+	}{
+		{"vi \x1b.\r", "vi /etc/hosts", 0},
+		{"vi \x1b.\x1b.\r", "vi b.txt", 0},
+		{"vi \x1b.\x1b.\x1b.\x1b.\r", "vi a.txt", 0},
+		{"\x1b.\x02\x1b.\r", "/etc/host/etc/hostss", 0},
+		// only the part that fit is taken back
+		{"vi \x1b.\r", "vi /e", 5},
+		{"vi \x1b.\x1b.\r", "vi b.", 5},
+		{"vi \x1b.\x1b.\x1b.\x1b.\r", "vi a.", 5},
+		{"vi a.txt\x1b.\x1b.\r", "vi a.", 5},
+	} {
+		e := &Terminal{
+			Inp:        bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:        bufio.NewWriter(&bytes.Buffer{}),
+			Prompt:     "> ",
+			MaxLineLen: tt.maxLen,
+			History:    History{Lines: []string{"cat a.txt", "  ", "cp a.txt b.txt", "ping -c1 /etc/hosts", ""}, Pos: 4},
+		}
+
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Error(err)
+		}
+		if l != tt.line {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.line, l)
+		}
+	}
+}
+
+func TestEditor_CharSearch(t *testing.T) {
+	for _, tt := range []struct {
+		in, line string
+	}{
+		{"a-b-c\x01\x1d-X\r", "aX-b-c"},
+		{"a-b-c\x01\x1d-\x1d-X\r", "a-bX-c"},
+		{"a-b-c\x1b\x1d-X\r", "a-bX-c"},
+		{"a-b-c\x1b\x1d-\x1b\x1d-X\r", "aX-b-c"},
+		{"abc\x01\x1dzX\r", "Xabc"},
+	} {
+		e := &Terminal{
+			Inp:    bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:    bufio.NewWriter(&bytes.Buffer{}),
+			Prompt: "> ",
+		}
+
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Error(err)
+		}
+		if l != tt.line {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.line, l)
+		}
+	}
+}
+
+func TestEditor_MaxLineLen(t *testing.T) {
+	for _, tt := range []struct {
+		in, line string
+	}{
+		{"abcdefg\r", "abcde"},
+		{"ab\x01" + strings.Repeat("x", 20) + "\r", "xxxab"},
+		{"abcde\x17\x19\x19\r", "abcde"},
+	} {
+		var out bytes.Buffer
+		e := &Terminal{
+			Inp:        bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:        bufio.NewWriter(&out),
+			Prompt:     "> ",
+			MaxLineLen: 5,
+		}
+
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Error(err)
+		}
+		if l != tt.line {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.line, l)
+		}
+		if !strings.Contains(out.String(), "\a") {
+			t.Errorf("%q: expected a bell", tt.in)
+		}
+	}
+}
+
+func TestEditor_LineCompleteMenu(t *testing.T) {
+	in := bytes.NewBuffer([]byte("git ch\t\tx\x0d"))
+
+	var calls int
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+		CompleteWord: func(line string, start, end int) []string {
+			return []string{"checkout", "cherry-pick"}
+		},
+		CompleteMenu: func(cs []Candidate) (Candidate, bool) {
+			calls++
+			if len(cs) != 2 || cs[1].Text != "cherry-pick" || cs[1].ReplaceFrom != 4 {
+				t.Errorf("unexpected candidates %#v", cs)
+			}
+			return cs[1], calls == 1
+		},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "git cherry-pickx" {
+		t.Errorf(`expected "git cherry-pickx" got %#v`, l)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls got %d", calls)
+	}
+}
+
+func TestEditor_LineHelpQuestionMark(t *testing.T) {
+	in := bytes.NewBuffer([]byte("a?\x16?\x0d"))
+
+	var calls int
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+		Help: func(string) [][2]string {
+			calls++
+			return [][2]string{{"a", "b"}}
+		},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "a??" || calls != 0 {
+		t.Errorf(`expected "a??" and no help got %#v and %d`, l, calls)
+	}
+
+	if err := e.Bind(Key{Rune: '?'}, "help"); err != nil {
+		t.Fatal(err)
+	}
+	in.WriteString("a?\x16?\x0d")
+	l, err = e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "a?" || calls != 1 {
+		t.Errorf(`expected "a?" and one help got %#v and %d`, l, calls)
+	}
+}
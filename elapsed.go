@@ -0,0 +1,79 @@
+package linenoisy
+
+import "time"
+
+const elapsedTick = 100 * time.Millisecond
+
+type elapsedTicker struct {
+	start time.Time
+	stop  chan struct{}
+	done  chan struct{}
+	shown bool
+}
+
+// Begin marks the start of the execution of an accepted line.
+// Until End, the Elapsed indicator is redrawn on the current line every 100ms.
+func (e *Terminal) Begin() {
+	e.End()
+
+	t := &elapsedTicker{
+		start: time.Now(),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	e.edit.Lock()
+	e.ticker = t
+	e.edit.Unlock()
+
+	if e.Elapsed == nil {
+		close(t.done)
+		return
+	}
+	go e.tick(t, e.Elapsed)
+}
+
+// End stops the indicator started by Begin, clears its line and returns the elapsed time.
+func (e *Terminal) End() time.Duration {
+	e.edit.Lock()
+	t := e.ticker
+	e.ticker = nil
+	e.edit.Unlock()
+
+	if t == nil {
+		return 0
+	}
+	close(t.stop)
+	<-t.done
+
+	if t.shown {
+		e.edit.Lock()
+		ew := errWriter{w: e.Out}
+		ew.writeString("\r" + e.caps().ClearEOL)
+		ew.flush()
+		e.edit.Unlock()
+	}
+	return time.Since(t.start)
+}
+
+func (e *Terminal) tick(t *elapsedTicker, elapsed func(time.Duration) string) {
+	defer close(t.done)
+
+	tk := time.NewTicker(elapsedTick)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case now := <-tk.C:
+			e.edit.Lock()
+			ew := errWriter{w: e.Out}
+			ew.writeString("\r")
+			ew.writeString(elapsed(now.Sub(t.start)))
+			ew.writeString(e.caps().ClearEOL)
+			ew.flush()
+			t.shown = true
+			e.edit.Unlock()
+		}
+	}
+}
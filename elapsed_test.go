@@ -0,0 +1,98 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEditor_BeginEnd(t *testing.T) {
+	out := &syncBuffer{}
+	ticked := make(chan struct{}, 1)
+
+	e := &Terminal{
+		Out: bufio.NewWriter(out),
+		Elapsed: func(d time.Duration) string {
+			select {
+			case ticked <- struct{}{}:
+			default:
+			}
+			return "running"
+		},
+	}
+
+	e.Begin()
+	<-ticked
+	d := e.End()
+
+	if d < elapsedTick {
+		t.Errorf("expected at least %s got %s", elapsedTick, d)
+	}
+	s := out.String()
+	if !strings.HasPrefix(s, "\rrunning\x1b[0K") || !strings.HasSuffix(s, "\r\x1b[0K") {
+		t.Errorf("unexpected %#v", s)
+	}
+
+	if d := e.End(); d != 0 {
+		t.Errorf("expected 0 got %s", d)
+	}
+}
+
+func TestEditor_BeginWriteOut(t *testing.T) {
+	// the transport is not synchronized, so go test -race reports any write to it outside the edit lock
+	ch := &rwc{Reader: bytes.NewBuffer(nil)}
+	ticks := make(chan struct{}, 16)
+
+	e := NewTerminal(ch, "> ")
+	e.Cols = 80
+	e.Elapsed = func(d time.Duration) string {
+		select {
+		case ticks <- struct{}{}:
+		default:
+		}
+		return "running"
+	}
+
+	e.Begin()
+	var wg sync.WaitGroup
+	for _, write := range []func([]byte) (int, error){e.WriteOut, e.Write} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; len(ticks) < 3; i++ {
+				if _, err := write(fmt.Appendf(nil, "log %d\n", i)); err != nil {
+					t.Error(err)
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+	e.End()
+
+	if s := ch.String(); !strings.Contains(s, "log 0\r\n") || !strings.Contains(s, "\rrunning\x1b[0K") {
+		t.Errorf("unexpected %q", s)
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -8,3 +8,5 @@ require (
 )
 
 require golang.org/x/sys v0.33.0 // indirect
+
+replace github.com/Joker/linenoisy => ../..
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"encoding/binary"
+	"errors"
 	"fmt"
 	"log"
 	"net"
@@ -79,13 +79,14 @@ func handleChannel(c ssh.NewChannel) {
 
 	go func() {
 		for req := range reqs {
+			if ok, err := e.HandleSSHRequest(req.Type, req.Payload); ok {
+				if req.WantReply {
+					req.Reply(err == nil, nil)
+				}
+				continue
+			}
+
 			switch req.Type {
-			case "pty-req":
-				termLen := req.Payload[3]
-				w, h := parseDims(req.Payload[termLen+4:])
-				e.Rows = h
-				e.Cols = w
-				req.Reply(true, nil)
 			case "shell":
 				term := string(req.Payload)
 				for _, t := range linenoisy.SupportedTerms {
@@ -95,10 +96,6 @@ func handleChannel(c ssh.NewChannel) {
 				}
 			case "exec":
 				log.Printf("exec: %s", req.Payload)
-			case "window-change":
-				w, h := parseDims(req.Payload)
-				e.Rows = h
-				e.Cols = w
 			default:
 				log.Printf("unknown req type: %s", req.Type)
 			}
@@ -107,6 +104,10 @@ func handleChannel(c ssh.NewChannel) {
 
 	for {
 		line, err := e.LineEditor()
+		if errors.Is(err, linenoisy.ErrInterrupted) {
+			e.Raw.Write([]byte("^C\r\n"))
+			continue
+		}
 		if err != nil {
 			break
 		}
@@ -122,19 +123,15 @@ func handleChannel(c ssh.NewChannel) {
 		e.History.Add(line)
 
 		if line == "adjust" {
-			log.Printf("adjusting: (%d, %d)\n", e.Cols, e.Rows)
+			cols, rows := e.Size()
+			log.Printf("adjusting: (%d, %d)\n", cols, rows)
 			e.Adjust()
-			log.Printf("adjusted: (%d, %d)\n", e.Cols, e.Rows)
+			cols, rows = e.Size()
+			log.Printf("adjusted: (%d, %d)\n", cols, rows)
 		}
 	}
 }
 
-func parseDims(b []byte) (int, int) {
-	w := int(binary.BigEndian.Uint32(b))
-	h := int(binary.BigEndian.Uint32(b[4:]))
-	return w, h
-}
-
 func serverPrivateKey() (ssh.Signer, error) {
 	b, err := serverPrivateKeyBytes()
 	if err != nil {
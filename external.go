@@ -0,0 +1,74 @@
+package linenoisy
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// editExternal hands the line over to ExternalEdit and puts the result back for editing,
+// with line breaks turned into spaces.
+func (e *Terminal) editExternal() error {
+	if e.ExternalEdit == nil {
+		return e.beep()
+	}
+
+	if err := e.suspend(); err != nil {
+		return err
+	}
+	l, err := e.ExternalEdit(string(e.Buffer))
+	if err == nil {
+		l = strings.ReplaceAll(strings.TrimRight(l, "\r\n"), "\r\n", "\n")
+		e.Buffer = []rune(strings.ReplaceAll(l, "\n", " ")) // the editor has no multi-line mode
+		e.Cur = len(e.Buffer)
+	}
+	if err := e.resume(); err != nil {
+		return err
+	}
+	if err != nil {
+		return e.beep()
+	}
+	return nil
+}
+
+// EditInEditor is an ExternalEdit for local terminals: it runs $VISUAL, $EDITOR or vi
+// on a temporary file holding current and returns the saved content.
+// The terminal has to be in cooked mode while the editor runs.
+func EditInEditor(current string) (string, error) {
+	f, err := os.CreateTemp("", "linenoisy-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(current + "\n"); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := strings.Fields(editor)
+
+	cmd := exec.Command(args[0], append(args[1:], f.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
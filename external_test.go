@@ -0,0 +1,21 @@
+package linenoisy
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestEditInEditor(t *testing.T) {
+	if _, err := exec.LookPath("sed"); err != nil {
+		t.Skip(err)
+	}
+	t.Setenv("VISUAL", "sed -i s/foo/baz/")
+
+	l, err := EditInEditor("foo bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "baz bar\n" {
+		t.Errorf(`expected "baz bar\n" got %#v`, l)
+	}
+}
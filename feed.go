@@ -0,0 +1,103 @@
+package linenoisy
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"iter"
+)
+
+// EventKind tells what happened while Feed handled input.
+type EventKind int
+
+const (
+	EventLine    EventKind = iota // LineEditor returned: Line was accepted, or abandoned with Err ErrInterrupted or io.EOF.
+	EventRefresh                  // the line was redrawn.
+	EventBell                     // the bell rang.
+)
+
+// Event is something that happened while Feed handled input.
+type Event struct {
+	Kind EventKind
+	Line string
+	Err  error
+}
+
+// feeder runs LineEditor as a coroutine which gets its input from Feed.
+type feeder struct {
+	inp     *bufio.Reader // Inp before Feed took over.
+	pending []byte
+	events  []Event
+	err     error
+	yield   func(struct{}) bool
+	next    func() (struct{}, bool)
+	stop    func()
+}
+
+var errFeedStopped = errors.New("feed stopped")
+
+// Read hands out the fed bytes and switches back to Feed when they are used up.
+func (f *feeder) Read(p []byte) (int, error) {
+	for len(f.pending) == 0 {
+		if !f.yield(struct{}{}) {
+			return 0, errFeedStopped
+		}
+	}
+	n := copy(p, f.pending)
+	f.pending = f.pending[n:]
+	return n, nil
+}
+
+// Feed handles the keys in b, which may end in the middle of one, and returns what happened, instead of
+// LineEditor reading Inp. Event loop programs (netpoll servers, WASM, GUIs) can drive the editor this way
+// without a goroutine blocked on input; output still goes to Out. After a line a new one is edited.
+//
+// A key which waits for more, like Ctrl-R, holds the editor, so WriteOut and the other locking methods
+// block until the input completing it is fed. EscTimeout and SeqTimeout have no effect.
+// An error, but ErrInterrupted or io.EOF from LineEditor, ends the editing; Feed returns it from then on.
+func (e *Terminal) Feed(b []byte) ([]Event, error) {
+	f := e.feed
+	if f == nil {
+		f = &feeder{inp: e.Inp}
+		e.feed = f
+		e.Inp = bufio.NewReader(f)
+		f.next, f.stop = iter.Pull(func(yield func(struct{}) bool) {
+			f.yield = yield
+			for {
+				l, err := e.LineEditor()
+				if err != nil && !errors.Is(err, ErrInterrupted) && !errors.Is(err, io.EOF) {
+					f.err = err
+					return
+				}
+				f.events = append(f.events, Event{Kind: EventLine, Line: l, Err: err})
+			}
+		})
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.pending = append(f.pending, b...)
+	f.next()
+
+	evs := f.events
+	f.events = nil
+	return evs, f.err
+}
+
+// StopFeed ends the editing driven by Feed and gives Inp back to LineEditor; the next Feed starts a new line.
+func (e *Terminal) StopFeed() {
+	if e.feed == nil {
+		return
+	}
+	e.feed.stop()
+	e.Inp = e.feed.inp
+	e.feed = nil
+}
+
+// emit records an event for Feed.
+func (e *Terminal) emit(k EventKind) {
+	if e.feed != nil {
+		e.feed.events = append(e.feed.events, Event{Kind: k})
+	}
+}
@@ -0,0 +1,68 @@
+package linenoisy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestEditor_Feed(t *testing.T) {
+	ch := &rwc{Reader: bytes.NewBuffer(nil)}
+	e := NewTerminal(ch, "> ")
+
+	lines := func(evs []Event) []Event {
+		return slices.DeleteFunc(evs, func(ev Event) bool { return ev.Kind != EventLine })
+	}
+
+	evs, err := e.Feed(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 1 || evs[0].Kind != EventRefresh {
+		t.Errorf("expected a refresh got %v", evs)
+	}
+
+	evs, err = e.Feed([]byte("fo\xc3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines(evs)) != 0 {
+		t.Errorf("expected no line got %v", evs)
+	}
+
+	evs, err = e.Feed([]byte("\xb6\x1b["))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Line() != "foö" {
+		t.Errorf(`expected "foö" got %#v`, e.Line())
+	}
+
+	evs, err = e.Feed([]byte("D\x7f\rbar\x03\x04"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Event{
+		{Kind: EventLine, Line: "fö"},
+		{Kind: EventLine, Line: "bar", Err: ErrInterrupted},
+		{Kind: EventLine, Line: "", Err: io.EOF},
+	}
+	if got := lines(evs); !slices.Equal(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+
+	evs, err = e.Feed([]byte("\x18"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evs, _ := e.Feed([]byte("\x07")); !slices.ContainsFunc(evs, func(ev Event) bool { return ev.Kind == EventBell }) {
+		t.Errorf("expected a bell got %v", evs)
+	}
+
+	e.StopFeed()
+	if _, err := e.LineEditor(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected EOF from Raw got %v", err)
+	}
+}
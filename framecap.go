@@ -0,0 +1,36 @@
+package linenoisy
+
+import "time"
+
+// deferRefresh reports whether a refresh is skipped because it comes within RefreshInterval of the last one
+// while more keys are waiting; flushRefresh draws it once the input has been caught up with.
+// Only the keys read by LineEditor are looked at, other goroutines always draw.
+//
+// Keys handled during an output burst of WriteOut are drawn by the output, or once the burst is over, see TypeaheadHold.
+func (e *Terminal) deferRefresh() bool {
+	if e.handling && e.outputBurst() {
+		e.refreshPending = true
+		e.holdRefresh()
+		return true
+	}
+	if e.RefreshInterval <= 0 || !e.handling {
+		return false
+	}
+	now := time.Now()
+	if e.buffered() > 0 && now.Sub(e.lastRefresh) < e.RefreshInterval {
+		e.refreshPending = true
+		return true
+	}
+	e.lastRefresh = now
+	e.refreshPending = false
+	return false
+}
+
+// flushRefresh draws a refresh skipped by deferRefresh.
+func (e *Terminal) flushRefresh() error {
+	if !e.refreshPending {
+		return nil
+	}
+	e.lastRefresh = time.Time{}
+	return e.refreshLine()
+}
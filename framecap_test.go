@@ -0,0 +1,36 @@
+package linenoisy
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Joker/linenoisy/vtest"
+)
+
+func TestEditor_RefreshInterval(t *testing.T) {
+	s := vtest.New(20, 3)
+	e := &Terminal{
+		Inp:             bufio.NewReader(strings.NewReader("abcdef\x02x\r")),
+		Out:             bufio.NewWriter(s),
+		Prompt:          "> ",
+		RefreshInterval: time.Hour,
+		Metrics:         &Metrics{},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "abcdexf" {
+		t.Errorf(`expected "abcdexf" got %q`, l)
+	}
+	// the empty line, the first key and the accepted line
+	if m := e.Metrics.Snapshot(); m.Refreshes != 3 {
+		t.Errorf("expected 3 refreshes got %d", m.Refreshes)
+	}
+	if s.Line(0) != "> abcdexf" {
+		t.Errorf(`expected "> abcdexf" got %q`, s.Line(0))
+	}
+}
@@ -0,0 +1,138 @@
+package linenoisy
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch is a candidate matching a pattern as a subsequence.
+type FuzzyMatch struct {
+	Text      string
+	Score     int
+	Positions []int // rune offsets of the matched characters in Text.
+}
+
+const (
+	fuzzyMatch       = 16
+	fuzzyWordStart   = 8 // the match begins a word, like "c" in "git checkout".
+	fuzzyConsecutive = 8
+	fuzzyGap         = 1 // per skipped character.
+)
+
+// FuzzyFilter returns the candidates containing the runes of pattern in order, ignoring case,
+// best matches first. Matches at word starts and runs of consecutive characters score higher,
+// so "gco" ranks "git checkout" above "gecko".
+func FuzzyFilter(pattern string, candidates []string) []FuzzyMatch {
+	var ms []FuzzyMatch
+	for _, c := range candidates {
+		if m, ok := fuzzyScore([]rune(strings.ToLower(pattern)), c); ok {
+			ms = append(ms, m)
+		}
+	}
+	slices.SortStableFunc(ms, func(a, b FuzzyMatch) int { return b.Score - a.Score })
+	return ms
+}
+
+func fuzzyScore(p []rune, text string) (FuzzyMatch, bool) {
+	t := []rune(text)
+	m := FuzzyMatch{Text: text}
+	if len(p) == 0 {
+		return m, true
+	}
+
+	// best[i][j] is the best score of p[:i+1] with p[i] matched at t[j], from[i][j] where p[i-1] matched.
+	const none = -1 << 30
+	best := make([][]int, len(p))
+	from := make([][]int, len(p))
+	for i := range p {
+		best[i] = make([]int, len(t))
+		from[i] = make([]int, len(t))
+		for j := range t {
+			best[i][j] = none
+			if unicode.ToLower(t[j]) != p[i] {
+				continue
+			}
+
+			bonus := fuzzyMatch
+			if j == 0 || strings.ContainsRune(" /-_.:", t[j-1]) {
+				bonus += fuzzyWordStart
+			}
+			if i == 0 {
+				best[i][j] = bonus - j*fuzzyGap
+				continue
+			}
+			for k := i - 1; k < j; k++ {
+				if best[i-1][k] == none {
+					continue
+				}
+				s := best[i-1][k] + bonus - (j-k-1)*fuzzyGap
+				if k == j-1 {
+					s += fuzzyConsecutive
+				}
+				if s > best[i][j] {
+					best[i][j], from[i][j] = s, k
+				}
+			}
+		}
+	}
+
+	last := len(p) - 1
+	end := -1
+	for j := range t {
+		if best[last][j] != none && (end < 0 || best[last][j] > best[last][end]) {
+			end = j
+		}
+	}
+	if end < 0 {
+		return m, false
+	}
+
+	m.Score = best[last][end]
+	m.Positions = make([]int, len(p))
+	for i := last; i >= 0; i-- {
+		m.Positions[i] = end
+		end = from[i][end]
+	}
+	return m, true
+}
+
+// fuzzyCandidates filters and ranks cs like FuzzyFilter, the pattern of a candidate being the text
+// it replaces up to the cursor. It returns the kept candidates and their highlighted texts.
+func (e *Terminal) fuzzyCandidates(cs []Candidate) ([]Candidate, []string) {
+	type match struct {
+		c Candidate
+		m FuzzyMatch
+	}
+	var ms []match
+	for _, c := range cs {
+		pattern := e.Buffer[c.ReplaceFrom:max(c.ReplaceFrom, min(e.Cur, c.ReplaceTo))]
+		if m, ok := fuzzyScore([]rune(strings.ToLower(string(pattern))), c.Text); ok {
+			ms = append(ms, match{c, m})
+		}
+	}
+	slices.SortStableFunc(ms, func(a, b match) int { return b.m.Score - a.m.Score })
+
+	var cells []string
+	cs = nil
+	for _, m := range ms {
+		cs = append(cs, m.c)
+		cells = append(cells, fuzzyHighlight(m.m))
+	}
+	return cs, cells
+}
+
+// fuzzyHighlight wraps the matched characters of m in Bold.
+func fuzzyHighlight(m FuzzyMatch) string {
+	var sb strings.Builder
+	for i, r := range []rune(m.Text) {
+		if slices.Contains(m.Positions, i) {
+			sb.Write(Bold)
+			sb.WriteRune(r)
+			sb.Write(Reset)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyFilter(t *testing.T) {
+	ms := FuzzyFilter("gco", []string{"gecko", "git checkout", "grep", "git commit", "GCO"})
+
+	var texts []string
+	for _, m := range ms {
+		texts = append(texts, m.Text)
+	}
+	if want := []string{"GCO", "git commit", "git checkout", "gecko"}; !slices.Equal(texts, want) {
+		t.Errorf("expected %q got %q", want, texts)
+	}
+	if want := []int{0, 4, 9}; !slices.Equal(ms[2].Positions, want) {
+		t.Errorf("expected %v got %v", want, ms[2].Positions)
+	}
+
+	if ms := FuzzyFilter("", []string{"a", "b"}); len(ms) != 2 {
+		t.Errorf("expected 2 matches got %d", len(ms))
+	}
+}
+
+func TestEditor_LineFuzzyComplete(t *testing.T) {
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp:           bufio.NewReader(bytes.NewBufferString("gco\t\x0d")),
+		Out:           bufio.NewWriter(&out),
+		Prompt:        "> ",
+		FuzzyComplete: true,
+		Complete: func(string) []string {
+			return []string{"git commit", "grep", "git checkout"}
+		},
+	}
+
+	if _, err := e.LineEditor(); err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(out.String(), "\x1b[1mg\x1b[0mit \x1b[1mc\x1b[0mhe") {
+		t.Errorf("expected highlighted candidates in %q", out.String())
+	}
+
+	e.Inp = bufio.NewReader(bytes.NewBufferString("gck\t\x0d"))
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "git checkout" {
+		t.Errorf(`expected "git checkout" got %#v`, l)
+	}
+}
@@ -0,0 +1,79 @@
+package linenoisy
+
+import (
+	"slices"
+	"unicode"
+)
+
+const zwj = '\u200d'
+
+// graphemeEnd returns the end of the grapheme cluster starting at rs[i]: a rune followed by
+// combining marks, variation selectors, emoji modifiers and tags, or runes joined by ZWJ;
+// a pair of regional indicators is a flag.
+// It is a subset of https://unicode.org/reports/tr29/ which is enough for editing.
+func graphemeEnd(rs []rune, i int) int {
+	if i >= len(rs) {
+		return len(rs)
+	}
+
+	j := i + 1
+	if isRegional(rs[i]) && j < len(rs) && isRegional(rs[j]) {
+		j++
+	}
+	for j < len(rs) {
+		switch {
+		case isExtend(rs[j]):
+			j++
+		case rs[j] == zwj:
+			j = min(j+2, len(rs))
+		default:
+			return j
+		}
+	}
+	return j
+}
+
+// graphemeStart returns the start of the grapheme cluster which ends at or contains rs[i-1].
+func graphemeStart(rs []rune, i int) int {
+	s := 0
+	for {
+		e := graphemeEnd(rs, s)
+		if e >= i {
+			return s
+		}
+		s = e
+	}
+}
+
+func isExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == '\u200c' || // ZWNJ
+		(r >= '\ufe00' && r <= '\ufe0f') || // variation selectors
+		(r >= 0xe0100 && r <= 0xe01ef) ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) || // emoji skin tone modifiers
+		(r >= 0xe0020 && r <= 0xe007f) // tags
+}
+
+func isRegional(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// runesWidth returns the number of columns rs occupies, a grapheme cluster takes the width of its first rune.
+func (e *Terminal) runesWidth(rs []rune) (w int) {
+	wc := e.WidthChar
+	if wc == nil {
+		wc = defaultWidth
+	}
+	for i, end := 0, 0; i < len(rs); i = end {
+		end = graphemeEnd(rs, i)
+		cw := wc(rs[i])
+		switch {
+		case cw == 1 && slices.Contains(rs[i+1:end], '\ufe0f'):
+			cw = 2 // emoji presentation of a text symbol
+		case isRegional(rs[i]) && end-i == 2:
+			cw = 2 // flag
+		}
+		w += cw
+	}
+	return
+}
@@ -0,0 +1,94 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestGraphemeEnd(t *testing.T) {
+	tests := []struct {
+		in  string
+		end []int // ends of the clusters
+	}{
+		{"abc", []int{1, 2, 3}},
+		{"e\u0301x", []int{2, 3}},
+		{"👩\u200d👩\u200d👧!", []int{5, 6}},
+		{"❤\ufe0f", []int{2}},
+		{"👍\U0001f3fda", []int{2, 3}},
+		{"🇯🇵🇫🇷", []int{2, 4}},
+		{"a\u200d", []int{2}},
+	}
+	for _, tt := range tests {
+		rs := []rune(tt.in)
+		var ends []int
+		for i := 0; i < len(rs); i = graphemeEnd(rs, i) {
+			ends = append(ends, graphemeEnd(rs, i))
+		}
+		if len(ends) != len(tt.end) {
+			t.Errorf("%q: expected %v got %v", tt.in, tt.end, ends)
+			continue
+		}
+		for i := range ends {
+			if ends[i] != tt.end[i] {
+				t.Errorf("%q: expected %v got %v", tt.in, tt.end, ends)
+				break
+			}
+			start := 0
+			if i > 0 {
+				start = ends[i-1]
+			}
+			if s := graphemeStart(rs, ends[i]); s != start {
+				t.Errorf("%q: expected start %d of %d got %d", tt.in, start, ends[i], s)
+			}
+		}
+	}
+}
+
+func TestEditor_LineGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		line string
+	}{
+		{"backspace", "ae\u0301\x7f\x0d", "a"},
+		{"delete", "e\u0301a\x01\x04\x0d", "a"},
+		{"left", "👩\u200d👩\u200d👧\x02a\x0d", "a👩\u200d👩\u200d👧"},
+		{"right", "👩\u200d👧b\x01\x06a\x0d", "👩\u200d👧ab"},
+		{"swap", "ae\u0301\x14\x0d", "e\u0301a"},
+		{"flag", "🇯🇵🇫🇷\x7f\x0d", "🇯🇵"},
+	}
+	for _, tt := range tests {
+		e := &Terminal{
+			Inp:    bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:    bufio.NewWriter(&bytes.Buffer{}),
+			Prompt: "> ",
+		}
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if l != tt.line {
+			t.Errorf("%s: expected %q got %q", tt.name, tt.line, l)
+		}
+	}
+}
+
+func TestEditor_LineGraphemesCursor(t *testing.T) {
+	in := bytes.NewBuffer([]byte("e\u0301\x02"))
+	out := &checkedWriter{
+		expectations: []string{
+			"\r> \x1b[0K\r\x1b[2C",
+			"\r> e\x1b[0K\r\x1b[3C",
+			"\r> e\u0301\x1b[0K\r\x1b[3C",
+			"\r> e\u0301\x1b[0K\r\x1b[2C",
+		},
+	}
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+	}
+	e.LineEditor()
+}
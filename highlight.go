@@ -0,0 +1,101 @@
+package linenoisy
+
+import "strings"
+
+var brackets = map[rune]rune{'(': ')', '[': ']', '{': '}', ')': '(', ']': '[', '}': '{'}
+
+// matchingBracket returns the offset in Buffer of the bracket matching the one under the cursor,
+// or else the one before the cursor; -1 if there is none.
+func (e *Terminal) matchingBracket() int {
+	for _, i := range []int{e.Cur, e.Cur - 1} {
+		if i < 0 || i >= len(e.Buffer) {
+			continue
+		}
+		b := e.Buffer[i]
+		m, ok := brackets[b]
+		if !ok {
+			continue
+		}
+
+		dir := 1
+		if strings.ContainsRune(")]}", b) {
+			dir = -1
+		}
+		depth := 0
+		for j := i; j >= 0 && j < len(e.Buffer); j += dir {
+			switch e.Buffer[j] {
+			case b:
+				depth++
+			case m:
+				depth--
+			}
+			if depth == 0 {
+				return j
+			}
+		}
+		return -1
+	}
+	return -1
+}
+
+// styled reports whether styledString changes the look of the line.
+func (e *Terminal) styled() bool {
+	return e.marked || e.MatchBrackets && e.matchingBracket() >= 0
+}
+
+// plain reports whether the terminal can't draw SGR attributes.
+func (e *Terminal) plain() bool {
+	return e.Dumb || e.caps().NoStyle
+}
+
+// styleHint wraps a non-empty hint in HintStyle and Reset, unless the terminal is plain.
+func (e *Terminal) styleHint(hint string) string {
+	style := e.HintStyle
+	if style == nil {
+		style = Dim
+	}
+	if hint == "" || len(style) == 0 || e.plain() {
+		return hint
+	}
+	return string(style) + hint + string(Reset)
+}
+
+// styledString returns rs, which starts at Buffer[off], with the selected region in reverse video
+// and the bracket matching the one at the cursor in bold when MatchBrackets is on.
+func (e *Terminal) styledString(rs []rune, off int) string {
+	if !e.styled() {
+		return string(rs)
+	}
+	from, to, marked := e.region()
+	match := -1
+	if e.MatchBrackets {
+		match = e.matchingBracket()
+	}
+
+	var (
+		sb  strings.Builder
+		rev bool
+	)
+	for i, r := range rs {
+		j := off + i
+		in := marked && from <= j && j < to
+		switch {
+		case in && !rev:
+			sb.WriteString("\x1b[7m")
+		case !in && rev:
+			sb.WriteString("\x1b[27m")
+		}
+		rev = in
+		if j == match {
+			sb.Write(Bold)
+			sb.WriteRune(r)
+			sb.WriteString("\x1b[22m")
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	if rev {
+		sb.WriteString("\x1b[27m")
+	}
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEditor_MatchingBracket(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		cur  int
+		want int
+	}{
+		{"(+ 1 (* 2 3))", 0, 12},
+		{"(+ 1 (* 2 3))", 13, 0},
+		{"(+ 1 (* 2 3))", 11, 5},
+		{"(+ 1 (* 2 3))", 5, 11},
+		{"[a {b} c]", 3, 5},
+		{"(a", 0, -1},
+		{"abc", 1, -1},
+	} {
+		e := &Terminal{Buffer: []rune(tt.line), Cur: tt.cur}
+		if got := e.matchingBracket(); got != tt.want {
+			t.Errorf("%q at %d: expected %d got %d", tt.line, tt.cur, tt.want, got)
+		}
+	}
+}
+
+func TestEditor_LineMatchBrackets(t *testing.T) {
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp:           bufio.NewReader(bytes.NewBufferString("(f x)\x0d")),
+		Out:           bufio.NewWriter(&out),
+		Prompt:        "> ",
+		MatchBrackets: true,
+	}
+	if _, err := e.LineEditor(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), "\r> \x1b[1m(\x1b[22mf x)\x1b[0K\r\x1b[7C") {
+		t.Errorf("expected the opening bracket in bold got %q", out.String())
+	}
+}
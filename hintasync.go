@@ -0,0 +1,54 @@
+package linenoisy
+
+import (
+	"context"
+	"time"
+)
+
+// asyncHint is the HintContext call for line.
+type asyncHint struct {
+	cancel context.CancelFunc
+	line   string
+	hint   string // the result, empty while pending.
+}
+
+// asyncHint returns the hint of the current line if it has arrived. For a changed line it cancels the call
+// for the previous one and starts a new one after HintDelay, which refreshes the line when the hint arrives.
+func (e *Terminal) asyncHint() string {
+	line := string(e.Buffer)
+	if h := e.hinting; h != nil && h.line == line {
+		return h.hint
+	}
+	if e.hinting != nil {
+		e.hinting.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &asyncHint{cancel: cancel, line: line}
+	e.hinting = h
+
+	delay, hint := e.HintDelay, e.HintContext
+	go func() {
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+
+		s := hint(ctx, line)
+
+		e.edit.Lock()
+		defer e.edit.Unlock()
+		if e.hinting != h || ctx.Err() != nil {
+			return
+		}
+		h.hint = s
+		if s != "" && e.active && !e.suspended {
+			// an error of the terminal is reported by the next key
+			e.refreshLine()
+		}
+	}()
+	return ""
+}
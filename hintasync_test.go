@@ -0,0 +1,53 @@
+package linenoisy
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHintContext(t *testing.T) {
+	r, w := io.Pipe()
+	out := &lockedBuffer{}
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	e := &Terminal{
+		Inp:       bufio.NewReader(r),
+		Out:       bufio.NewWriter(out),
+		Prompt:    "> ",
+		HintDelay: 20 * time.Millisecond,
+		HintContext: func(ctx context.Context, line string) string {
+			mu.Lock()
+			calls = append(calls, line)
+			mu.Unlock()
+			return map[string]string{"gi": "t status"}[line]
+		},
+	}
+
+	done := make(chan string)
+	go func() {
+		l, _ := e.LineEditor()
+		done <- l
+	}()
+
+	w.Write([]byte("gi"))
+	waitFor(t, "the hint", func() bool { return strings.Contains(out.String(), "\r> gi\x1b[2mt status") })
+	w.Write([]byte("\r"))
+	if l := <-done; l != "gi" {
+		t.Errorf(`expected "gi" got %#v`, l)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if slices.Contains(calls, "g") || !slices.Contains(calls, "gi") {
+		t.Errorf(`expected a call for "gi" and none for "g" got %q`, calls)
+	}
+}
@@ -0,0 +1,507 @@
+package linenoisy
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// History keeps entered lines for navigation. Its methods are safe for concurrent use.
+type History struct {
+	Lines []string
+	Pos   int
+
+	Shared *History // OPTIONAL; Entries are added to and read from this history shared with other sessions; navigation stays per session.
+
+	IgnoreDups  bool // OPTIONAL; Add skips a line identical to the previous entry.
+	EraseDups   bool // OPTIONAL; Add removes older entries identical to the line.
+	IgnoreSpace bool // OPTIONAL; Add skips lines beginning with a space.
+
+	notes map[int]string // annotations by index in Lines.
+
+	appendPath string // file every added entry is appended to.
+	err        error  // first error of appending to appendPath.
+
+	mu sync.Mutex
+}
+
+func (h *History) Add(l string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.shared() {
+		h.Shared.Add(l)
+		h.sync("")
+		return
+	}
+
+	if len(h.Lines) == 0 {
+		h.Lines = []string{""}
+	}
+	last := len(h.Lines) - 1
+
+	if (h.IgnoreSpace && strings.HasPrefix(l, " ")) ||
+		(h.IgnoreDups && last > 0 && h.Lines[last-1] == l) {
+		h.Lines[last] = ""
+		h.Pos = last
+		return
+	}
+
+	if h.EraseDups {
+		for i := last - 1; i >= 0; i-- {
+			if h.Lines[i] == l {
+				h.remove(i)
+			}
+		}
+	}
+
+	h.add(l)
+
+	if h.appendPath != "" {
+		h.appendFile(l)
+	}
+}
+
+func (h *History) shared() bool {
+	return h.Shared != nil && h.Shared != h
+}
+
+// sync replaces the entries by the ones of the shared history and moves to the line being edited.
+func (h *History) sync(editing string) {
+	h.Shared.mu.Lock()
+	lines := slices.Clone(h.Shared.Lines)
+	h.Shared.mu.Unlock()
+
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	lines[len(lines)-1] = editing
+	h.Lines = lines
+	h.Pos = len(lines) - 1
+}
+
+// editing returns the line being edited.
+func (h *History) editing() string {
+	if len(h.Lines) == 0 {
+		return ""
+	}
+	return h.Lines[len(h.Lines)-1]
+}
+
+// entries returns a copy of Lines.
+func (h *History) entries() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return slices.Clone(h.Lines)
+}
+
+// remove deletes Lines[i] keeping the annotations of the other entries.
+func (h *History) remove(i int) {
+	h.Lines = slices.Delete(h.Lines, i, i+1)
+	if h.notes == nil {
+		return
+	}
+	notes := make(map[int]string, len(h.notes))
+	for k, v := range h.notes {
+		switch {
+		case k < i:
+			notes[k] = v
+		case k > i:
+			notes[k-1] = v
+		}
+	}
+	h.notes = notes
+}
+
+// add stores l without appending it to the file.
+func (h *History) add(l string) {
+	if len(h.Lines) == 0 {
+		h.Lines = []string{""}
+	}
+	h.Lines[len(h.Lines)-1] = l
+	h.Lines = append(h.Lines, "")
+	h.Pos = len(h.Lines) - 1
+}
+
+func (h *History) Next() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.Pos >= len(h.Lines)-1 {
+		return errors.New("end of history")
+	}
+	h.Pos++
+	return nil
+}
+
+func (h *History) Prev() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.shared() && h.Pos >= len(h.Lines)-1 {
+		h.sync(h.editing()) // pick up entries of other sessions
+	}
+	if h.Pos <= 0 {
+		return errors.New("beginning of history")
+	}
+	h.Pos--
+	return nil
+}
+
+// First moves to the oldest entry.
+func (h *History) First() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.shared() && h.Pos >= len(h.Lines)-1 {
+		h.sync(h.editing())
+	}
+	if h.Pos <= 0 {
+		return errors.New("beginning of history")
+	}
+	h.Pos = 0
+	return nil
+}
+
+// Last moves back to the line being edited.
+func (h *History) Last() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.Pos >= len(h.Lines)-1 {
+		return errors.New("end of history")
+	}
+	h.Pos = len(h.Lines) - 1
+	return nil
+}
+
+// view returns a copy of Lines and Pos, after picking up the entries of other sessions like Prev.
+func (h *History) view() ([]string, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.shared() && h.Pos >= len(h.Lines)-1 {
+		h.sync(h.editing())
+	}
+	return slices.Clone(h.Lines), h.Pos
+}
+
+// moveTo moves to Lines[i].
+func (h *History) moveTo(i int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if i >= 0 && i < len(h.Lines) {
+		h.Pos = i
+	}
+}
+
+// seek moves to the newest entry equal to l, after picking up the entries of other sessions like Prev.
+// It reports whether there is one.
+func (h *History) seek(l string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.shared() && h.Pos >= len(h.Lines)-1 {
+		h.sync(h.editing())
+	}
+	for i := len(h.Lines) - 2; i >= 0; i-- {
+		if h.Lines[i] == l {
+			h.Pos = i
+			return true
+		}
+	}
+	return false
+}
+
+func (h *History) Get() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.Lines[h.Pos]
+}
+
+func (h *History) Save(l string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.Lines) == 0 {
+		h.Lines = []string{""}
+	}
+	if h.Pos != len(h.Lines)-1 {
+		return
+	}
+	h.Lines[len(h.Lines)-1] = l
+}
+
+// Annotate attaches a note (exit status, duration, error, ...) to the most recently added entry.
+func (h *History) Annotate(note string) {
+	if h.shared() {
+		h.Shared.Annotate(note)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.annotate(note)
+}
+
+func (h *History) annotate(note string) {
+	i := len(h.Lines) - 2
+	if i < 0 {
+		return
+	}
+	if h.notes == nil {
+		h.notes = map[int]string{}
+	}
+	if note == "" {
+		delete(h.notes, i)
+		return
+	}
+	h.notes[i] = note
+}
+
+// Annotation returns the note attached to Lines[i].
+func (h *History) Annotation(i int) string {
+	if h.shared() {
+		return h.Shared.Annotation(i)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.notes[i]
+}
+
+type historyEntry struct {
+	Line string `json:"line"`
+	Note string `json:"note,omitempty"`
+}
+
+// MarshalJSON exports the entries with their annotations.
+func (h *History) MarshalJSON() ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := []historyEntry{}
+	for i, l := range h.Lines {
+		if i == len(h.Lines)-1 {
+			break // the line being edited
+		}
+		entries = append(entries, historyEntry{Line: l, Note: h.notes[i]})
+	}
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON replaces the entries by the ones produced by MarshalJSON.
+func (h *History) UnmarshalJSON(b []byte) error {
+	var entries []historyEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.Lines = nil
+	h.notes = nil
+	for _, en := range entries {
+		h.add(en.Line)
+		h.annotate(en.Note)
+	}
+	if len(h.Lines) == 0 {
+		h.Lines = []string{""}
+		h.Pos = 0
+	}
+	return nil
+}
+
+// LoadFile appends the entries of a linenoise/readline compatible history file (one entry per line).
+func (h *History) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		l := strings.TrimSuffix(sc.Text(), "\r")
+		if l == "" {
+			continue
+		}
+		h.add(l)
+	}
+	return sc.Err()
+}
+
+// SaveFile atomically replaces path with the entries, one per line, readable only by the owner.
+func (h *History) SaveFile(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op after a successful rename
+
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+
+	h.mu.Lock()
+	lines := slices.Clone(h.Lines)
+	h.mu.Unlock()
+
+	w := bufio.NewWriter(f)
+	for i, l := range lines {
+		if i == len(lines)-1 {
+			break // the line being edited
+		}
+		w.WriteString(l)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// AppendTo makes every following Add append its entry to path right away (like `history -a`),
+// so a crash or a dropped connection doesn't lose the session's history.
+// An empty path turns appending off.
+func (h *History) AppendTo(path string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.appendPath = ""
+	h.err = nil
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	h.appendPath = path
+	return nil
+}
+
+// Err returns the first error of appending entries to the file set by AppendTo.
+func (h *History) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.err
+}
+
+func (h *History) appendFile(l string) {
+	f, err := os.OpenFile(h.appendPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err == nil {
+		_, err = f.WriteString(l + "\n")
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil && h.err == nil {
+		h.err = err
+	}
+}
+
+// Expand replaces bang-style history references in line:
+// `!!` is the previous entry, `!n` the n-th entry, `!-n` the n-th previous one
+// and `!prefix` the most recent entry starting with prefix.
+func (h *History) Expand(line string) (string, error) {
+	h.mu.Lock()
+	if h.shared() && h.Pos >= len(h.Lines)-1 {
+		h.sync(h.editing())
+	}
+	entries := slices.Clone(h.Lines)
+	h.mu.Unlock()
+
+	if len(entries) > 0 {
+		entries = entries[:len(entries)-1] // without the line being edited
+	}
+
+	rs := []rune(line)
+	var sb strings.Builder
+	for i := 0; i < len(rs); i++ {
+		if rs[i] != '!' || i+1 == len(rs) || strings.ContainsRune(" \t=(", rs[i+1]) {
+			sb.WriteRune(rs[i])
+			continue
+		}
+
+		j := i + 1
+		var (
+			ev = ""
+			ok = false
+		)
+		switch {
+		case rs[j] == '!':
+			j++
+			if len(entries) > 0 {
+				ev, ok = entries[len(entries)-1], true
+			}
+		case rs[j] == '-' || (rs[j] >= '0' && rs[j] <= '9'):
+			k := j + 1
+			for k < len(rs) && rs[k] >= '0' && rs[k] <= '9' {
+				k++
+			}
+			n, err := strconv.Atoi(string(rs[j:k]))
+			if err == nil {
+				if n < 0 {
+					n += len(entries) + 1
+				}
+				if n >= 1 && n <= len(entries) {
+					ev, ok = entries[n-1], true
+				}
+			}
+			j = k
+		default:
+			k := j
+			for k < len(rs) && !unicode.IsSpace(rs[k]) && !strings.ContainsRune(";&|", rs[k]) {
+				k++
+			}
+			prefix := string(rs[j:k])
+			for n := len(entries) - 1; n >= 0; n-- {
+				if strings.HasPrefix(entries[n], prefix) {
+					ev, ok = entries[n], true
+					break
+				}
+			}
+			j = k
+		}
+
+		if !ok {
+			return line, fmt.Errorf("%s: event not found", string(rs[i:j]))
+		}
+		sb.WriteString(ev)
+		i = j - 1
+	}
+	return sb.String(), nil
+}
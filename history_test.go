@@ -0,0 +1,247 @@
+package linenoisy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestHistory_Annotate(t *testing.T) {
+	var h History
+	h.Annotate("ignored")
+	h.Add("ls")
+	h.Annotate("exit 0")
+	h.Add("false")
+	h.Annotate("exit 1")
+	h.Add("true")
+
+	if a := h.Annotation(0); a != "exit 0" {
+		t.Errorf(`expected "exit 0" got %#v`, a)
+	}
+	if a := h.Annotation(2); a != "" {
+		t.Errorf(`expected "" got %#v`, a)
+	}
+
+	b, err := json.Marshal(&h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); s != `[{"line":"ls","note":"exit 0"},{"line":"false","note":"exit 1"},{"line":"true"}]` {
+		t.Errorf("unexpected %s", s)
+	}
+
+	var h2 History
+	if err := json.Unmarshal(b, &h2); err != nil {
+		t.Fatal(err)
+	}
+	if len(h2.Lines) != 4 || h2.Lines[1] != "false" || h2.Pos != 3 {
+		t.Errorf("unexpected %#v at %d", h2.Lines, h2.Pos)
+	}
+	if a := h2.Annotation(1); a != "exit 1" {
+		t.Errorf(`expected "exit 1" got %#v`, a)
+	}
+}
+
+func TestHistory_SaveFileLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	var h History
+	h.Add("foo")
+	h.Add("bar baz")
+	if err := h.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "foo\nbar baz\n" {
+		t.Errorf(`expected "foo\nbar baz\n" got %#v`, string(b))
+	}
+	if fi, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if runtime.GOOS != "windows" && fi.Mode().Perm() != 0600 {
+		t.Errorf("expected 0600 got %o", fi.Mode().Perm())
+	}
+
+	var h2 History
+	h2.Add("qux")
+	if err := h2.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"qux", "foo", "bar baz", ""}; !slices.Equal(h2.Lines, want) {
+		t.Errorf("expected %#v got %#v", want, h2.Lines)
+	}
+}
+
+func TestHistory_AppendTo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var h History
+	if err := h.AppendTo(path); err != nil {
+		t.Fatal(err)
+	}
+	h.Add("foo")
+	h.Add("bar")
+	if err := h.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "old\nfoo\nbar\n" {
+		t.Errorf(`expected "old\nfoo\nbar\n" got %#v`, string(b))
+	}
+
+	if err := h.AppendTo(""); err != nil {
+		t.Fatal(err)
+	}
+	h.Add("baz")
+	if b, _ := os.ReadFile(path); string(b) != "old\nfoo\nbar\n" {
+		t.Errorf("unexpected %#v", string(b))
+	}
+}
+
+func TestHistory_Dups(t *testing.T) {
+	h := History{IgnoreDups: true, IgnoreSpace: true}
+	for _, l := range []string{"ls", "ls", " secret", "cd", "ls"} {
+		h.Save("edited")
+		h.Add(l)
+	}
+	if want := []string{"ls", "cd", "ls", ""}; !slices.Equal(h.Lines, want) {
+		t.Errorf("expected %#v got %#v", want, h.Lines)
+	}
+	if h.Pos != 3 {
+		t.Errorf("expected 3 got %d", h.Pos)
+	}
+
+	h = History{EraseDups: true}
+	h.Add("ls")
+	h.Add("cd")
+	h.Annotate("cd note")
+	h.Add("ls")
+	if want := []string{"cd", "ls", ""}; !slices.Equal(h.Lines, want) {
+		t.Errorf("expected %#v got %#v", want, h.Lines)
+	}
+	if a := h.Annotation(0); a != "cd note" {
+		t.Errorf(`expected "cd note" got %#v`, a)
+	}
+}
+
+func TestHistory_Expand(t *testing.T) {
+	var h History
+	h.Add("ls -l")
+	h.Add("git status")
+	h.Add("echo hi")
+
+	for in, want := range map[string]string{
+		"!!":         "echo hi",
+		"sudo !!":    "sudo echo hi",
+		"!1 /tmp":    "ls -l /tmp",
+		"!-2":        "git status",
+		"!gi":        "git status",
+		"a != b":     "a != b",
+		"wow!":       "wow!",
+		"!l; !!":     "ls -l; echo hi",
+		"no bang":    "no bang",
+		"x=!(y)":     "x=!(y)",
+		"!e && !1!!": "echo hi && ls -lecho hi",
+	} {
+		got, err := h.Expand(in)
+		if err != nil {
+			t.Errorf("%#v: %v", in, err)
+		}
+		if got != want {
+			t.Errorf("%#v: expected %#v got %#v", in, want, got)
+		}
+	}
+
+	for _, in := range []string{"!4", "!-4", "!nope"} {
+		if _, err := h.Expand(in); err == nil {
+			t.Errorf("%#v: err expected", in)
+		}
+	}
+}
+
+func TestHistory_Shared(t *testing.T) {
+	shared := &History{IgnoreDups: true}
+	a := History{Shared: shared}
+	b := History{Shared: shared}
+
+	a.Add("ls")
+	b.Add("cd")
+	b.Add("cd")
+	a.Save("editing")
+
+	if err := a.Prev(); err != nil {
+		t.Fatal(err)
+	}
+	if l := a.Get(); l != "cd" {
+		t.Errorf(`expected "cd" got %#v`, l)
+	}
+	if err := a.Prev(); err != nil {
+		t.Fatal(err)
+	}
+	if l := a.Get(); l != "ls" {
+		t.Errorf(`expected "ls" got %#v`, l)
+	}
+
+	b.Add("pwd")
+	if l := a.Get(); l != "ls" {
+		t.Errorf("expected navigation of a to stay on \"ls\" got %#v", l)
+	}
+	if err := a.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if l := a.Get(); l != "editing" {
+		t.Errorf(`expected "editing" got %#v`, l)
+	}
+	if err := a.Prev(); err != nil {
+		t.Fatal(err)
+	}
+	if l := a.Get(); l != "pwd" {
+		t.Errorf(`expected "pwd" got %#v`, l)
+	}
+
+	if want := []string{"ls", "cd", "pwd", ""}; !slices.Equal(shared.Lines, want) {
+		t.Errorf("expected %#v got %#v", want, shared.Lines)
+	}
+}
+
+func TestHistory_SharedConcurrent(t *testing.T) {
+	shared := &History{}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := History{Shared: shared}
+			for j := range 100 {
+				h.Add(fmt.Sprintf("%d-%d", i, j))
+				h.Prev()
+				h.Get()
+				h.Next()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(shared.Lines); n != 801 {
+		t.Errorf("expected 801 got %d", n)
+	}
+}
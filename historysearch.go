@@ -0,0 +1,112 @@
+package linenoisy
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SearchMode selects how History.Search matches its pattern.
+type SearchMode int
+
+const (
+	SearchSubstring SearchMode = iota // the pattern appears in the entry.
+	SearchGlob                        // the whole entry matches the pattern with *, ? and [...] wildcards.
+	SearchRegexp                      // the entry contains a match of the regexp/syntax pattern.
+)
+
+// SearchOptions adjust History.Search.
+type SearchOptions struct {
+	Mode       SearchMode
+	IgnoreCase bool
+	Limit      int // OPTIONAL; at most this many matches, all without a limit.
+}
+
+// Match is an entry found by History.Search, Start and End are the rune offsets of the matched text in Line.
+type Match struct {
+	Index      int // of the entry in Lines.
+	Line       string
+	Start, End int
+}
+
+// Search returns the entries matching pattern, the newest first; the line being edited isn't searched.
+// An invalid regexp is reported as error.
+func (h *History) Search(pattern string, opts SearchOptions) ([]Match, error) {
+	var expr string
+	switch opts.Mode {
+	case SearchGlob:
+		expr = "^" + globRegexp(pattern) + "$"
+	case SearchRegexp:
+		expr = pattern
+	default:
+		expr = regexp.QuoteMeta(pattern)
+	}
+	if opts.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := h.entries()
+	if h.shared() {
+		lines = h.Shared.entries()
+	}
+	return searchLines(lines, re, opts.Limit), nil
+}
+
+// searchLines returns up to limit matches of re in lines, the newest first, leaving out the last line,
+// which is the one being edited.
+func searchLines(lines []string, re *regexp.Regexp, limit int) []Match {
+	var ms []Match
+	for i := len(lines) - 2; i >= 0; i-- {
+		loc := re.FindStringIndex(lines[i])
+		if loc == nil {
+			continue
+		}
+		ms = append(ms, Match{
+			Index: i,
+			Line:  lines[i],
+			Start: utf8.RuneCountInString(lines[i][:loc[0]]),
+			End:   utf8.RuneCountInString(lines[i][:loc[1]]),
+		})
+		if len(ms) == limit {
+			break
+		}
+	}
+	return ms
+}
+
+// globRegexp translates the wildcards of a glob pattern, the other runes match themselves.
+func globRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return sb.String()
+}
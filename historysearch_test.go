@@ -0,0 +1,47 @@
+package linenoisy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHistory_Search(t *testing.T) {
+	h := &History{Lines: []string{"git commit -m 'wip'", "ls -l", "Git push", "grep -r wip .", "git"}}
+
+	for _, tt := range []struct {
+		pattern string
+		opts    SearchOptions
+		want    []Match
+	}{
+		{"wip", SearchOptions{}, []Match{
+			{Index: 3, Line: "grep -r wip .", Start: 8, End: 11},
+			{Index: 0, Line: "git commit -m 'wip'", Start: 15, End: 18},
+		}},
+		{"git", SearchOptions{IgnoreCase: true, Limit: 1}, []Match{
+			{Index: 2, Line: "Git push", Start: 0, End: 3},
+		}},
+		{"g?? *", SearchOptions{Mode: SearchGlob}, []Match{
+			{Index: 0, Line: "git commit -m 'wip'", Start: 0, End: 19},
+		}},
+		{"[!g]s -[a-z]", SearchOptions{Mode: SearchGlob}, []Match{
+			{Index: 1, Line: "ls -l", Start: 0, End: 5},
+		}},
+		{`-\w\b`, SearchOptions{Mode: SearchRegexp}, []Match{
+			{Index: 3, Line: "grep -r wip .", Start: 5, End: 7},
+			{Index: 1, Line: "ls -l", Start: 3, End: 5},
+			{Index: 0, Line: "git commit -m 'wip'", Start: 11, End: 13},
+		}},
+	} {
+		ms, err := h.Search(tt.pattern, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(ms, tt.want) {
+			t.Errorf("%q: expected %+v got %+v", tt.pattern, tt.want, ms)
+		}
+	}
+
+	if _, err := h.Search("(", SearchOptions{Mode: SearchRegexp}); err == nil {
+		t.Error("expected an error for an invalid regexp")
+	}
+}
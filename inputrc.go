@@ -0,0 +1,317 @@
+package linenoisy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadInputrc reads the readline init file of the user, $INPUTRC or ~/.inputrc; a missing file is no error.
+func (e *Terminal) LoadInputrc() error {
+	path := os.Getenv("INPUTRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(home, ".inputrc")
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return e.ReadInputrc(f)
+}
+
+// ReadInputrc applies a subset of the GNU readline init file syntax:
+//
+//	# comment
+//	set enable-bracketed-paste on
+//	"\C-u": kill-whole-line
+//	Meta-y: yank
+//	$if mode=emacs
+//	...
+//	$endif
+//
+// Bindings take the names of Bind and a single key, in quotes with the escapes \C-, \M-, \e, \\, \",
+// \t, \r, \n, \d, octal and hex, or as a key name like Control-u, M-DEL or RET.
+// Of the variables, editing-mode emacs, show-all-if-ambiguous on, enable-bracketed-paste and keyseq-timeout are supported,
+// the others are ignored like readline ignores unknown ones. Macros and multi-key sequences are reported.
+// Lines with errors are skipped, their errors are returned together after the rest has been applied.
+func (e *Terminal) ReadInputrc(r io.Reader) error {
+	var (
+		errs []error
+		skip []bool // for every open $if, whether its lines are skipped.
+	)
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '$' {
+			directive, arg, _ := strings.Cut(line[1:], " ")
+			switch directive {
+			case "if":
+				// only the emacs mode exists, terms and application names don't match
+				on := strings.ReplaceAll(strings.TrimSpace(arg), " ", "") == "mode=emacs"
+				skip = append(skip, !on || skipping(skip))
+			case "else":
+				if len(skip) == 0 {
+					errs = append(errs, fmt.Errorf("inputrc line %d: $else without $if", n))
+					continue
+				}
+				outer := skipping(skip[:len(skip)-1])
+				skip[len(skip)-1] = outer || !skip[len(skip)-1]
+			case "endif":
+				if len(skip) == 0 {
+					errs = append(errs, fmt.Errorf("inputrc line %d: $endif without $if", n))
+					continue
+				}
+				skip = skip[:len(skip)-1]
+			default:
+				if !skipping(skip) {
+					errs = append(errs, fmt.Errorf("inputrc line %d: unsupported directive $%s", n, directive))
+				}
+			}
+			continue
+		}
+		if skipping(skip) {
+			continue
+		}
+
+		var err error
+		if rest, ok := strings.CutPrefix(line, "set "); ok {
+			err = e.setVariable(strings.Fields(rest))
+		} else {
+			err = e.bindInputrc(line)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("inputrc line %d: %w", n, err))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func skipping(skip []bool) bool {
+	return len(skip) > 0 && skip[len(skip)-1]
+}
+
+func (e *Terminal) setVariable(f []string) error {
+	if len(f) < 2 {
+		return fmt.Errorf("set needs a variable and a value")
+	}
+	name, value := strings.ToLower(f[0]), f[1]
+	on := strings.EqualFold(value, "on") || value == "1"
+
+	switch name {
+	case "editing-mode":
+		if value != "emacs" {
+			return fmt.Errorf("unsupported editing-mode %s", value)
+		}
+	case "show-all-if-ambiguous":
+		if !on {
+			return fmt.Errorf("unsupported show-all-if-ambiguous %s, candidates are listed on the first Tab", value)
+		}
+	case "enable-bracketed-paste":
+		e.BracketedPaste = on
+	case "keyseq-timeout":
+		ms, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid keyseq-timeout %s", value)
+		}
+		e.EscTimeout = time.Duration(max(ms, 0)) * time.Millisecond
+	}
+	return nil
+}
+
+func (e *Terminal) bindInputrc(line string) error {
+	var seq, name string
+	if line[0] == '"' {
+		end := 1
+		for end < len(line) && line[end] != '"' {
+			if line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(line) {
+			return fmt.Errorf("unterminated key sequence")
+		}
+		var err error
+		if seq, err = unescapeKeyseq(line[1:end]); err != nil {
+			return err
+		}
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line[end+1:]), ":")
+		if !ok {
+			return fmt.Errorf("missing ':' after the key sequence")
+		}
+		name = strings.TrimSpace(rest)
+	} else {
+		keyname, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("missing ':' after the key name")
+		}
+		var err error
+		if seq, err = keynameSeq(strings.TrimSpace(keyname)); err != nil {
+			return err
+		}
+		name = strings.TrimSpace(rest)
+	}
+
+	if strings.HasPrefix(name, `"`) || strings.HasPrefix(name, "'") {
+		return fmt.Errorf("macros are not supported")
+	}
+	if name == "" {
+		return fmt.Errorf("missing function name")
+	}
+
+	kt := &Terminal{Inp: bufio.NewReader(strings.NewReader(seq))}
+	k, err := kt.readKey()
+	if err != nil || kt.Inp.Buffered() > 0 {
+		return fmt.Errorf("key sequence %q is not a single key", seq)
+	}
+	return e.Bind(k, name)
+}
+
+// unescapeKeyseq decodes the escapes of a quoted inputrc key sequence.
+func unescapeKeyseq(s string) (string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", fmt.Errorf("trailing backslash")
+		}
+		switch c := s[i]; c {
+		case 'M':
+			if i+1 >= len(s) || s[i+1] != '-' {
+				return "", fmt.Errorf(`incomplete \M-`)
+			}
+			i++
+			sb.WriteByte(esc) // the key follows, it may be escaped itself
+		case 'C':
+			if i+2 >= len(s) || s[i+1] != '-' {
+				return "", fmt.Errorf(`incomplete \C-`)
+			}
+			i += 2
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			sb.WriteByte(controlChar(s[i]))
+		case 'e':
+			sb.WriteByte(esc)
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'd':
+			sb.WriteByte(backspace)
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case 'x':
+			j := i + 1
+			for j < len(s) && j < i+3 && strings.IndexByte("0123456789abcdefABCDEF", s[j]) >= 0 {
+				j++
+			}
+			n, err := strconv.ParseUint(s[i+1:j], 16, 8)
+			if err != nil {
+				return "", fmt.Errorf(`invalid \x escape`)
+			}
+			sb.WriteByte(byte(n))
+			i = j - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			j := i
+			for j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			n, err := strconv.ParseUint(s[i:j], 8, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid octal escape")
+			}
+			sb.WriteByte(byte(n))
+			i = j - 1
+		default: // \\, \", \' and the like
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), nil
+}
+
+var inputrcKeys = map[string]string{
+	"del":     "\x7f",
+	"rubout":  "\x7f",
+	"esc":     "\x1b",
+	"escape":  "\x1b",
+	"lfd":     "\n",
+	"newline": "\n",
+	"ret":     "\r",
+	"return":  "\r",
+	"space":   " ",
+	"spc":     " ",
+	"tab":     "\t",
+}
+
+// keynameSeq returns the key sequence of an inputrc key name like Control-u, M-b or RET.
+func keynameSeq(name string) (string, error) {
+	lower := strings.ToLower(name)
+	for _, p := range []string{"control-", "c-"} {
+		if rest, ok := strings.CutPrefix(lower, p); ok {
+			seq, err := keynameSeq(name[len(name)-len(rest):])
+			if err != nil || len(seq) != 1 {
+				return "", fmt.Errorf("invalid key name %s", name)
+			}
+			return string(controlChar(seq[0])), nil
+		}
+	}
+	for _, p := range []string{"meta-", "m-"} {
+		if rest, ok := strings.CutPrefix(lower, p); ok {
+			seq, err := keynameSeq(name[len(name)-len(rest):])
+			if err != nil {
+				return "", err
+			}
+			return "\x1b" + seq, nil
+		}
+	}
+	if seq, ok := inputrcKeys[lower]; ok {
+		return seq, nil
+	}
+	if len([]rune(name)) != 1 {
+		return "", fmt.Errorf("invalid key name %s", name)
+	}
+	return name, nil
+}
+
+// controlChar returns the control character of c, Ctrl-? being DEL.
+func controlChar(c byte) byte {
+	if c == '?' {
+		return backspace
+	}
+	return c & 0x1f
+}
@@ -0,0 +1,73 @@
+package linenoisy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEditor_ReadInputrc(t *testing.T) {
+	rc := `# bindings
+set editing-mode emacs
+set enable-bracketed-paste on
+"\C-u": kill-whole-line
+Control-g: beginning-of-line
+"\e[A": history-search-backward
+M-y: yank
+"\M-\C-k": kill-line
+$if mode=vi
+"\C-a": end-of-line
+$else
+"\C-e": beginning-of-line
+$endif
+$if Bash
+"\C-b": end-of-line
+$endif
+`
+	e := &Terminal{}
+	if err := e.ReadInputrc(strings.NewReader(rc)); err != nil {
+		t.Fatal(err)
+	}
+	if !e.BracketedPaste {
+		t.Error("expected BracketedPaste")
+	}
+
+	want := map[Key]string{
+		ctrl('u'):                          "kill-whole-line",
+		ctrl('g'):                          "beginning-of-line",
+		{Code: KeyUp}:                      "history-search-backward",
+		{Rune: 'y', Mod: ModAlt}:           "yank",
+		{Rune: 'k', Mod: ModCtrl | ModAlt}: "kill-line",
+		ctrl('e'):                          "beginning-of-line",
+	}
+	if len(e.bindings) != len(want) {
+		t.Errorf("expected %d bindings got %d", len(want), len(e.bindings))
+	}
+	for k, name := range want {
+		if f, ok := e.bindings[k]; !ok || reflect.ValueOf(f).Pointer() != reflect.ValueOf(editFuncs[name]).Pointer() {
+			t.Errorf("expected %s bound to %s", k, name)
+		}
+	}
+}
+
+func TestEditor_ReadInputrcErrors(t *testing.T) {
+	rc := `set editing-mode vi
+"\C-xp": "macro"
+"\C-x\C-e": yank
+"\C-w": no-such-function
+"\C-y": yank
+`
+	e := &Terminal{}
+	err := e.ReadInputrc(strings.NewReader(rc))
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, s := range []string{"line 1:", "line 2:", "line 3:", "line 4:"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected an error of %s got %v", s, err)
+		}
+	}
+	if _, ok := e.bindings[ctrl('y')]; !ok {
+		t.Error("expected the valid binding after the errors")
+	}
+}
@@ -0,0 +1,106 @@
+package linenoisy
+
+import (
+	"regexp"
+	"slices"
+)
+
+// editIncrementalSearch searches the history for entries containing the typed text while it is typed,
+// older ones first for dir < 0 (Ctrl-R), newer ones for dir > 0 (Ctrl-S). Ctrl-R and Ctrl-S go on to the next
+// match in their direction, so a match passed with Ctrl-R can be reached again by Ctrl-S; Backspace shortens
+// the search. Ctrl-G puts the line back as it was, any other key takes the match and is returned to be handled.
+func (e *Terminal) editIncrementalSearch(dir int) (Key, bool, error) {
+	line, cur, prompt := string(e.Buffer), e.Cur, e.Prompt
+	e.History.Save(line)
+	lines, hpos := e.History.view() // searched and shown alike, other sessions may add entries meanwhile
+	if len(lines) == 0 {
+		lines = []string{line}
+	}
+	origin := len(lines) - 1
+	if hpos >= 0 && hpos < len(lines) {
+		origin = hpos
+	}
+
+	var query []rune
+	pos, start, failed := origin, -1, false
+	find := func(from int) {
+		ms := searchLines(lines, regexp.MustCompile(regexp.QuoteMeta(string(query))), 0) // the newest first
+		if dir > 0 {
+			slices.Reverse(ms)
+		}
+		for _, m := range ms {
+			if (dir < 0 && m.Index <= from) || (dir > 0 && m.Index >= from) {
+				pos, start, failed = m.Index, m.Start, false
+				return
+			}
+		}
+		failed = true
+	}
+	defer func() { e.Prompt = prompt }()
+
+	for {
+		e.Prompt = isearchPrompt(dir, failed, string(query))
+		e.Buffer = []rune(lines[pos])
+		e.Cur = len(e.Buffer)
+		if start >= 0 {
+			e.Cur = min(start, len(e.Buffer))
+		}
+		if err := e.repaint(); err != nil {
+			return Key{}, false, err
+		}
+
+		k, err := e.readKey()
+		if err != nil {
+			return k, false, err
+		}
+		switch {
+		case k == ctrl('r'), k == ctrl('s'):
+			if dir = -1; k == ctrl('s') {
+				dir = 1
+			}
+			if len(query) > 0 {
+				find(pos + dir)
+			}
+		case k == Key{Code: KeyBackspace}, k == ctrl('h'):
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				pos, start = origin, -1
+				if len(query) > 0 {
+					find(origin)
+				}
+			}
+		case k == ctrl('g'):
+			e.Buffer = []rune(line)
+			e.Cur = cur
+			e.History.moveTo(len(lines) - 1)
+			e.Prompt = prompt
+			return k, false, e.repaint()
+		case k.Code == KeyRune && k.Mod == 0:
+			query = append(query, k.Rune)
+			find(pos)
+		default:
+			if pos != len(lines)-1 {
+				e.History.moveTo(pos)
+				e.historyUsed = true
+			}
+			e.Prompt = prompt
+			return k, true, e.repaint()
+		}
+		if failed {
+			if err := e.beep(); err != nil {
+				return k, false, err
+			}
+		}
+	}
+}
+
+func isearchPrompt(dir int, failed bool, query string) string {
+	p := "(reverse-i-search)`"
+	if dir > 0 {
+		p = "(i-search)`"
+	}
+	if failed {
+		p = "(failed " + p[1:]
+	}
+	return p + query + "': "
+}
@@ -0,0 +1,86 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEditor_IncrementalSearch(t *testing.T) {
+	for _, tt := range []struct {
+		name, in, line string
+	}{
+		{"reverse", "\x12git\r", "git push"},
+		{"older match", "\x12git\x12\r", "git commit"},
+		{"forward after overshooting", "\x12git\x12\x13\r", "git push"},
+		{"backspace", "\x12lx\x7f\r", "ls"},
+		{"cancel", "ab\x12zz\x07\r", "ab"},
+		{"edit the match", "\x12ls\x05 -l\r", "ls -l"},
+	} {
+		var out bytes.Buffer
+		e := &Terminal{
+			Inp:     bufio.NewReader(strings.NewReader(tt.in)),
+			Out:     bufio.NewWriter(&out),
+			Prompt:  "> ",
+			History: History{Lines: []string{"git commit", "ls", "git push", ""}, Pos: 3},
+		}
+
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l != tt.line {
+			t.Errorf("%s: expected %q got %q", tt.name, tt.line, l)
+		}
+	}
+}
+
+func TestEditor_IncrementalSearchShared(t *testing.T) {
+	for _, tt := range []struct {
+		name, in, line string
+	}{
+		{"reverse", "\x12ma\r", "make"},
+		{"newest", "\x12p\r", "pwd"},
+		{"older match", "\x12l\x12\r", "ls"},
+		{"forward", "\x13pw\r", ""},
+	} {
+		shared := &History{}
+		other := History{Shared: shared}
+		e := &Terminal{
+			Inp:     bufio.NewReader(strings.NewReader(tt.in)),
+			Out:     bufio.NewWriter(&bytes.Buffer{}),
+			Prompt:  "> ",
+			History: History{Shared: shared},
+		}
+		e.History.Add("ls")
+		other.Add("make") // not seen by e yet
+		other.Add("pwd")
+
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l != tt.line {
+			t.Errorf("%s: expected %q got %q", tt.name, tt.line, l)
+		}
+	}
+}
+
+func TestEditor_IncrementalSearchPrompt(t *testing.T) {
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp:     bufio.NewReader(strings.NewReader("\x12gi\x13x\r")),
+		Out:     bufio.NewWriter(&out),
+		Prompt:  "> ",
+		History: History{Lines: []string{"git commit", ""}, Pos: 1},
+	}
+	if _, err := e.LineEditor(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"(reverse-i-search)`gi': git commit", "(failed i-search)`gix': git commit"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in %q", want, out.String())
+		}
+	}
+}
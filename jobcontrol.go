@@ -0,0 +1,25 @@
+package linenoisy
+
+// editStop stops the process on Ctrl-Z like a shell job when Raw is a local terminal:
+// the terminal is put in cooked mode until the process is continued, then the line is drawn again.
+func (e *Terminal) editStop() error {
+	f, ok := e.Raw.(interface{ Fd() uintptr })
+	if !jobControl || !ok || !isTerminal(f.Fd()) {
+		return nil // a remote client has no job control of ours
+	}
+
+	if err := e.suspend(); err != nil {
+		return err
+	}
+	if err := e.Out.Flush(); err != nil {
+		return err
+	}
+	err := stopProcess(f.Fd())
+	if rerr := e.resume(); rerr != nil {
+		return rerr
+	}
+	if err != nil {
+		return e.beep()
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package linenoisy
+
+import "errors"
+
+const jobControl = false
+
+func stopProcess(fd uintptr) error {
+	return errors.New("job control is not supported")
+}
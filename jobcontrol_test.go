@@ -0,0 +1,22 @@
+package linenoisy
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEditor_LineCtrlZRemote(t *testing.T) {
+	ch := &rwc{Reader: bytes.NewBufferString("a\x1ab\r")}
+	e := NewTerminal(ch, "> ")
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "ab" {
+		t.Errorf(`expected "ab" got %#v`, l)
+	}
+	if bytes.Contains(ch.Bytes(), []byte("\x1b[0J")) {
+		t.Errorf("expected no suspend in %q", ch.String())
+	}
+}
@@ -0,0 +1,50 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package linenoisy
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+const jobControl = true
+
+// stopProcess restores cooked mode on fd, sends SIGTSTP to the process group
+// and puts the terminal modes back once SIGCONT arrives.
+func stopProcess(fd uintptr) error {
+	var raw syscall.Termios
+	if err := termios(fd, ioctlGetTermios, &raw); err != nil {
+		return err
+	}
+
+	cooked := raw
+	cooked.Iflag |= syscall.ICRNL | syscall.IXON
+	cooked.Oflag |= syscall.OPOST | syscall.ONLCR
+	cooked.Lflag |= syscall.ECHO | syscall.ECHOE | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	if err := termios(fd, ioctlSetTermios, &cooked); err != nil {
+		return err
+	}
+
+	cont := make(chan os.Signal, 1)
+	signal.Notify(cont, syscall.SIGCONT)
+	defer signal.Stop(cont)
+
+	err := syscall.Kill(0, syscall.SIGTSTP)
+	if err == nil {
+		<-cont
+	}
+	if serr := termios(fd, ioctlSetTermios, &raw); serr != nil {
+		return serr
+	}
+	return err
+}
+
+func termios(fd, req uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,513 @@
+package linenoisy
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Key is a decoded key press.
+//
+// Terminals encode the same key in different ways (Ctrl-Space as NUL, Ctrl-/ as 0x1f,
+// CSI u sequences, ...); they all decode to the same Key. Letters of Ctrl combinations are lower case.
+type Key struct {
+	Code KeyCode // KeyRune for characters.
+	Rune rune    // the character of a KeyRune key.
+	Mod  Mod
+}
+
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyUnknown
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyDelete
+	KeyInsert
+	KeyPageUp
+	KeyPageDown
+	KeyPaste   // start of a bracketed paste.
+	KeyWheelUp // mouse wheel, reported when Mouse is on.
+	KeyWheelDown
+	KeyF1
+	KeyClipboard // the clipboard content the terminal sent on request, see Clipboard.
+)
+
+// Mod is a set of key modifiers. The bits match the xterm modifier parameter minus one.
+type Mod uint8
+
+const (
+	ModShift Mod = 1 << iota
+	ModAlt
+	ModCtrl
+)
+
+var keyNames = map[KeyCode]string{
+	KeyUnknown:   "Unknown",
+	KeyEnter:     "Enter",
+	KeyTab:       "Tab",
+	KeyBackspace: "Backspace",
+	KeyEscape:    "Escape",
+	KeyUp:        "Up",
+	KeyDown:      "Down",
+	KeyRight:     "Right",
+	KeyLeft:      "Left",
+	KeyHome:      "Home",
+	KeyEnd:       "End",
+	KeyDelete:    "Delete",
+	KeyInsert:    "Insert",
+	KeyPageUp:    "PageUp",
+	KeyPageDown:  "PageDown",
+	KeyPaste:     "Paste",
+	KeyWheelUp:   "WheelUp",
+	KeyWheelDown: "WheelDown",
+	KeyF1:        "F1",
+	KeyClipboard: "Clipboard",
+}
+
+// String returns names like "a", "Ctrl-A", "Alt-Left".
+func (k Key) String() string {
+	var sb strings.Builder
+	if k.Mod&ModCtrl != 0 {
+		sb.WriteString("Ctrl-")
+	}
+	if k.Mod&ModAlt != 0 {
+		sb.WriteString("Alt-")
+	}
+	if k.Mod&ModShift != 0 {
+		sb.WriteString("Shift-")
+	}
+
+	switch {
+	case k.Code != KeyRune:
+		sb.WriteString(keyNames[k.Code])
+	case k.Rune == ' ':
+		sb.WriteString("Space")
+	case k.Mod&ModCtrl != 0:
+		sb.WriteRune(unicode.ToUpper(k.Rune))
+	default:
+		sb.WriteRune(k.Rune)
+	}
+	return sb.String()
+}
+
+func ctrl(r rune) Key {
+	return Key{Rune: r, Mod: ModCtrl}
+}
+
+// readKey reads and decodes one key press from Inp.
+func (e *Terminal) readKey() (Key, error) {
+	if e.stream != nil {
+		return e.streamKey()
+	}
+	if err := e.waitPeek(); err != nil {
+		return Key{}, err
+	}
+
+	r, _, err := e.Inp.ReadRune()
+	for err == nil && e.seqLost != 0 && e.lostRune(r) {
+		r, _, err = e.Inp.ReadRune()
+	}
+	if err != nil {
+		return Key{}, err
+	}
+	if r == esc {
+		timed := e.feed == nil // Feed's input can't be waited for in the background
+		if timed && e.EscTimeout > 0 && e.Inp.Buffered() == 0 && !e.waitInput(e.EscTimeout) {
+			return Key{Code: KeyEscape}, nil
+		}
+		if timed && e.SeqTimeout > 0 {
+			e.seqDeadline = time.Now().Add(e.SeqTimeout)
+		}
+		k, err := e.readEscape()
+		e.seqDeadline = time.Time{}
+		if err == errSeqTimeout {
+			return Key{Code: KeyUnknown}, nil
+		}
+		return k, err
+	}
+	return decodeRune(r), nil
+}
+
+// errSeqTimeout ends an escape sequence whose rest didn't arrive within SeqTimeout.
+var errSeqTimeout = errors.New("escape sequence timed out")
+
+// seqRune reads the next rune of an escape sequence. With SeqTimeout it gives up once the deadline
+// of the sequence passed without input, the wait for the input goes on like the one of EscTimeout.
+func (e *Terminal) seqRune() (rune, error) {
+	if !e.seqDeadline.IsZero() && e.Inp.Buffered() == 0 {
+		if d := time.Until(e.seqDeadline); d <= 0 || !e.waitInput(d) {
+			return 0, errSeqTimeout
+		}
+	}
+	r, _, err := e.Inp.ReadRune()
+	return r, err
+}
+
+// lostRune reports whether r is part of the late rest of the escape sequence given up by SeqTimeout:
+// the bytes up to the final byte of a CSI or SS3, the string up to BEL or ST of an OSC. It forgets the sequence at its end or at the first rune which can't belong to it.
+func (e *Terminal) lostRune(r rune) bool {
+	lost := e.seqLost
+	e.seqLost = 0
+	switch lost {
+	case '[', 'O':
+		if r >= 0x20 && r <= 0x3f {
+			e.seqLost = lost
+		}
+		return r >= 0x20 && r <= 0x7e
+	case ']':
+		switch {
+		case r == esc:
+			e.seqLost = '\\'
+		case r >= ' ':
+			e.seqLost = lost
+		}
+		return r >= ' ' || r == '\a' || r == esc
+	case '\\': // after the ESC of ST
+		return r == '\\'
+	}
+	return false
+}
+
+// waitInput reports whether input arrives within d. Otherwise the wait goes on in the background,
+// the next readKey picks it up, so no other reader may use Inp in the meantime.
+func (e *Terminal) waitInput(d time.Duration) bool {
+	ch := make(chan error, 1)
+	go func() {
+		_, err := e.Inp.Peek(1)
+		ch <- err
+	}()
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ch:
+		return true
+	case <-t.C:
+		e.peek = ch
+		return false
+	}
+}
+
+// waitPeek waits for the end of the background wait of waitInput, after which Inp can be read again.
+func (e *Terminal) waitPeek() error {
+	if e.peek == nil {
+		return nil
+	}
+	err := <-e.peek
+	e.peek = nil
+	return err
+}
+
+// readReply reads up to and including delim, like the answer of the terminal to a query of Adjust or Probe.
+func (e *Terminal) readReply(delim byte) (string, error) {
+	if err := e.waitPeek(); err != nil {
+		return "", err
+	}
+	return e.Inp.ReadString(delim)
+}
+
+// buffered returns how many bytes of input can be read without waiting. While the wait of waitInput
+// goes on in the background, or KeyEvents reads Inp, it must not be touched and nothing counts as buffered.
+func (e *Terminal) buffered() int {
+	if e.peek != nil || e.stream != nil {
+		return 0
+	}
+	return e.Inp.Buffered()
+}
+
+func decodeRune(r rune) Key {
+	switch {
+	case r == enter:
+		return Key{Code: KeyEnter}
+	case r == tab:
+		return Key{Code: KeyTab}
+	case r == backspace:
+		return Key{Code: KeyBackspace}
+	case r == esc:
+		return Key{Code: KeyEscape}
+	case r == 0:
+		return ctrl(' ')
+	case r < esc:
+		return ctrl('a' + r - 1)
+	case r < ' ':
+		return ctrl(rune(`\]^/`[r-28]))
+	}
+	return Key{Rune: r}
+}
+
+func (e *Terminal) readEscape() (_ Key, err error) {
+	var intro rune
+	defer func() {
+		if err == errSeqTimeout && e.seqLost == 0 {
+			e.seqLost = intro
+		}
+	}()
+
+	r, err := e.seqRune()
+	if err != nil {
+		return Key{}, err
+	}
+	intro = r
+
+	switch r {
+	case '[':
+		r, err := e.seqRune()
+		if err != nil {
+			return Key{}, err
+		}
+		seq, err := e.readCSI(r)
+		if err != nil {
+			return Key{}, err
+		}
+		if seq == "" {
+			return Key{Code: KeyUnknown}, nil
+		}
+		if seq == "M" { // X10 mouse report, the button, column and row follow as bytes
+			var b [3]rune
+			for i := range b {
+				if b[i], err = e.seqRune(); err != nil {
+					return Key{}, err
+				}
+			}
+			return decodeMouse(int(b[0]) - 32), nil
+		}
+		k := decodeCSI(seq)
+		if k.Code == KeyPaste {
+			e.pasted, err = e.readPaste()
+		}
+		return k, err
+	case 'O':
+		r, err := e.seqRune()
+		if err != nil {
+			return Key{}, err
+		}
+		switch r {
+		case 'H':
+			return Key{Code: KeyHome}, nil
+		case 'F':
+			return Key{Code: KeyEnd}, nil
+		case 'P':
+			return Key{Code: KeyF1}, nil
+		}
+		return Key{Code: KeyUnknown}, nil
+	case ']':
+		seq, err := e.readOSC()
+		if err != nil {
+			return Key{}, err
+		}
+		return e.decodeOSC(seq), nil
+	}
+
+	k := decodeRune(r)
+	k.Mod |= ModAlt
+	return k, nil
+}
+
+// maxCSI and maxOSC cap the control sequences and OSC strings kept in memory while they are read.
+// The rest of a longer one is read and discarded and it decodes as KeyUnknown.
+const (
+	maxCSI = 64
+	maxOSC = 1 << 16
+)
+
+// readCSI reads the rest of a control sequence starting with r after `ESC [`
+// and returns it up to and including the final byte, or "" if it's longer than maxCSI.
+func (e *Terminal) readCSI(r rune) (string, error) {
+	var sb strings.Builder
+	for n := 1; ; n++ {
+		if n <= maxCSI {
+			sb.WriteRune(r)
+		}
+		if r >= 0x40 && r <= 0x7e {
+			if n > maxCSI {
+				return "", nil
+			}
+			return sb.String(), nil
+		}
+
+		var err error
+		r, err = e.seqRune()
+		if err != nil {
+			return sb.String(), err
+		}
+	}
+}
+
+// readOSC reads an operating system command after `ESC ]` up to BEL or ST and returns it without them,
+// or "" if it's longer than maxOSC.
+func (e *Terminal) readOSC() (string, error) {
+	var sb strings.Builder
+	for n := 0; ; n++ {
+		r, err := e.seqRune()
+		if err != nil {
+			return sb.String(), err
+		}
+		switch r {
+		case '\a':
+		case esc:
+			if _, err := e.seqRune(); err != nil { // the backslash of ST
+				if err == errSeqTimeout {
+					e.seqLost = '\\'
+				}
+				return sb.String(), err
+			}
+		default:
+			if n < maxOSC {
+				sb.WriteRune(r)
+			}
+			continue
+		}
+		if n > maxOSC {
+			return "", nil
+		}
+		return sb.String(), nil
+	}
+}
+
+// decodeCSI decodes the part of a control sequence after `ESC [`.
+func decodeCSI(seq string) Key {
+	params, final := seq[:len(seq)-1], seq[len(seq)-1]
+
+	switch final {
+	case 'A', 'B', 'C', 'D', 'H', 'F', 'P': // also CSI 1 ; modifiers A
+		one, mods, _ := strings.Cut(params, ";")
+		if one != "" && one != "1" {
+			break
+		}
+		return Key{Code: map[byte]KeyCode{
+			'A': KeyUp,
+			'B': KeyDown,
+			'C': KeyRight,
+			'D': KeyLeft,
+			'H': KeyHome,
+			'F': KeyEnd,
+			'P': KeyF1,
+		}[final], Mod: decodeMod(mods)}
+	case '~': // CSI number ; modifiers ~
+		code, mods, _ := strings.Cut(params, ";")
+		if code == "27" { // CSI 27 ; modifiers ; code ~ of xterm's modifyOtherKeys
+			mods, code, _ = strings.Cut(mods, ";")
+			n, err := strconv.Atoi(code)
+			if err != nil {
+				break
+			}
+			return decodeModified(n, mods)
+		}
+		kc, ok := tildeKeys[code]
+		if !ok {
+			break
+		}
+		return Key{Code: kc, Mod: decodeMod(mods)}
+	case 'M', 'm': // SGR mouse report: CSI < button ; column ; row M
+		b, ok := strings.CutPrefix(params, "<")
+		if !ok {
+			break
+		}
+		b, _, _ = strings.Cut(b, ";")
+		n, err := strconv.Atoi(b)
+		if err != nil || final == 'm' {
+			break
+		}
+		return decodeMouse(n)
+	case 'Z': // back tab
+		return Key{Code: KeyTab, Mod: ModShift}
+	case 'u': // CSI code[:alternates] ; modifiers[:event] ; text u, see KittyKeyboard
+		code, rest, _ := strings.Cut(params, ";")
+		code, _, _ = strings.Cut(code, ":")
+		mods, _, _ := strings.Cut(rest, ";")
+		mods, event, _ := strings.Cut(mods, ":")
+		n, err := strconv.Atoi(code)
+		if err != nil || event == "3" { // key release
+			break
+		}
+		return decodeModified(n, mods)
+	}
+	return Key{Code: KeyUnknown}
+}
+
+// decodeModified decodes the code point n of a key pressed with the modifiers param.
+func decodeModified(n int, param string) Key {
+	k, ok := kittyKeys[n]
+	if !ok && n >= 0xe000 && n <= 0xf8ff {
+		return Key{Code: KeyUnknown} // other functional keys are encoded in the private use area
+	}
+	if !ok {
+		k = decodeRune(rune(n))
+	}
+	k.Mod |= decodeMod(param)
+	switch {
+	case k.Mod&ModCtrl != 0:
+		k.Rune = unicode.ToLower(k.Rune)
+	case k.Mod == ModShift && k.Code == KeyRune:
+		k = Key{Rune: unicode.ToUpper(k.Rune)}
+	}
+	return k
+}
+
+// tildeKeys are the keys of `ESC [ number ~` sequences; terminals disagree on Home and End.
+var tildeKeys = map[string]KeyCode{
+	"1":   KeyHome,
+	"2":   KeyInsert,
+	"3":   KeyDelete,
+	"4":   KeyEnd,
+	"5":   KeyPageUp,
+	"6":   KeyPageDown,
+	"7":   KeyHome,
+	"8":   KeyEnd,
+	"11":  KeyF1,
+	"200": KeyPaste,
+}
+
+// kittyKeys are the keypad keys of the kitty keyboard protocol, they do what the main keys do.
+var kittyKeys = map[int]Key{
+	57399: {Rune: '0'}, 57400: {Rune: '1'}, 57401: {Rune: '2'}, 57402: {Rune: '3'}, 57403: {Rune: '4'},
+	57404: {Rune: '5'}, 57405: {Rune: '6'}, 57406: {Rune: '7'}, 57407: {Rune: '8'}, 57408: {Rune: '9'},
+	57409: {Rune: '.'}, 57410: {Rune: '/'}, 57411: {Rune: '*'}, 57412: {Rune: '-'}, 57413: {Rune: '+'},
+	57414: {Code: KeyEnter}, 57415: {Rune: '='},
+	57417: {Code: KeyLeft}, 57418: {Code: KeyRight}, 57419: {Code: KeyUp}, 57420: {Code: KeyDown},
+	57421: {Code: KeyPageUp}, 57422: {Code: KeyPageDown}, 57423: {Code: KeyHome}, 57424: {Code: KeyEnd},
+	57425: {Code: KeyInsert}, 57426: {Code: KeyDelete},
+}
+
+// decodeMod decodes the xterm modifier parameter, 1 plus the modifier bits.
+func decodeMod(param string) Mod {
+	m, err := strconv.Atoi(param)
+	if err != nil || m <= 1 {
+		return 0
+	}
+	return Mod(m-1) & (ModShift | ModAlt | ModCtrl)
+}
+
+// decodeMouse decodes the button of a mouse report, only the wheel is a key.
+func decodeMouse(b int) Key {
+	var mod Mod
+	if b&4 != 0 {
+		mod |= ModShift
+	}
+	if b&8 != 0 {
+		mod |= ModAlt
+	}
+	if b&16 != 0 {
+		mod |= ModCtrl
+	}
+
+	switch b &^ (4 | 8 | 16) {
+	case 64:
+		return Key{Code: KeyWheelUp, Mod: mod}
+	case 65:
+		return Key{Code: KeyWheelDown, Mod: mod}
+	}
+	return Key{Code: KeyUnknown}
+}
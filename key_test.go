@@ -0,0 +1,262 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEditor_readKey(t *testing.T) {
+	for in, want := range map[string]Key{
+		"a":              {Rune: 'a'},
+		"ж":              {Rune: 'ж'},
+		"\x01":           ctrl('a'),
+		"\x00":           ctrl(' '),
+		"\x1f":           ctrl('/'),
+		"\x1d":           ctrl(']'),
+		"\x0d":           {Code: KeyEnter},
+		"\x7f":           {Code: KeyBackspace},
+		"\x1bx":          {Rune: 'x', Mod: ModAlt},
+		"\x1b\x01":       {Rune: 'a', Mod: ModAlt | ModCtrl},
+		"\x1b[A":         {Code: KeyUp},
+		"\x1b[1;5C":      {Code: KeyRight, Mod: ModCtrl},
+		"\x1b[1;3D":      {Code: KeyLeft, Mod: ModAlt},
+		"\x1b[1;2A":      {Code: KeyUp, Mod: ModShift},
+		"\x1b[1;6H":      {Code: KeyHome, Mod: ModCtrl | ModShift},
+		"\x1b[2;5C":      {Code: KeyUnknown},
+		"\x1bOH":         {Code: KeyHome},
+		"\x1bOP":         {Code: KeyF1},
+		"\x1b[11~":       {Code: KeyF1},
+		"\x1b[1;2P":      {Code: KeyF1, Mod: ModShift},
+		"\x1b[3~":        {Code: KeyDelete},
+		"\x1b[2~":        {Code: KeyInsert},
+		"\x1b[1~":        {Code: KeyHome},
+		"\x1b[7~":        {Code: KeyHome},
+		"\x1b[4~":        {Code: KeyEnd},
+		"\x1b[8~":        {Code: KeyEnd},
+		"\x1b[5~":        {Code: KeyPageUp},
+		"\x1b[6~":        {Code: KeyPageDown},
+		"\x1b[3;5~":      {Code: KeyDelete, Mod: ModCtrl},
+		"\x1b[6;2~":      {Code: KeyPageDown, Mod: ModShift},
+		"\x1b[<64;10;5M": {Code: KeyWheelUp},
+		"\x1b[<65;1;1M":  {Code: KeyWheelDown},
+		"\x1b[<80;1;1M":  {Code: KeyWheelUp, Mod: ModCtrl},
+		"\x1b[<0;1;1M":   {Code: KeyUnknown},
+		"\x1b[M`!!":      {Code: KeyWheelUp},
+		"\x1b[Ma!!":      {Code: KeyWheelDown},
+		"\x1b[97;5u":     ctrl('a'),
+		"\x1b[65;5u":     ctrl('a'),
+		"\x1b[32;5u":     ctrl(' '),
+		"\x1b[47;5u":     ctrl('/'),
+		"\x1b[13;5u":     {Code: KeyEnter, Mod: ModCtrl},
+		"\x1b[97;3u":     {Rune: 'a', Mod: ModAlt},
+		"\x1b[97;6u":     {Rune: 'a', Mod: ModCtrl | ModShift},
+		"\x1b[97:65;6u":  {Rune: 'a', Mod: ModCtrl | ModShift},
+		"\x1b[97;2u":     {Rune: 'A'},
+		"\x1b[97;5:3u":   {Code: KeyUnknown},
+		"\x1b[9;2u":      {Code: KeyTab, Mod: ModShift},
+		"\x1b[Z":         {Code: KeyTab, Mod: ModShift},
+		"\x1b[27u":       {Code: KeyEscape},
+		"\x1b[57414;5u":  {Code: KeyEnter, Mod: ModCtrl},
+		"\x1b[57399u":    {Rune: '0'},
+		"\x1b[57441;2u":  {Code: KeyUnknown},
+		"\x1b[27;5;13~":  {Code: KeyEnter, Mod: ModCtrl},
+		"\x1b[27;2;9~":   {Code: KeyTab, Mod: ModShift},
+		"\x1b[27;6;65~":  {Rune: 'a', Mod: ModCtrl | ModShift},
+		"\x1b[27;2;33~":  {Rune: '!'},
+		"\x1b[27;5;49~":  {Rune: '1', Mod: ModCtrl},
+		"\x1b[27;3;120~": {Rune: 'x', Mod: ModAlt},
+		"\x1b[27;5~":     {Code: KeyUnknown},
+		"\x1b[99;99~":    {Code: KeyUnknown},
+	} {
+		e := &Terminal{Inp: bufio.NewReader(strings.NewReader(in))}
+		k, err := e.readKey()
+		if err != nil {
+			t.Errorf("%#v: %v", in, err)
+		}
+		if k != want {
+			t.Errorf("%#v: expected %v got %v", in, want, k)
+		}
+	}
+}
+
+func TestKey_String(t *testing.T) {
+	for k, want := range map[Key]string{
+		{Rune: 'a'}:                        "a",
+		ctrl('a'):                          "Ctrl-A",
+		ctrl(' '):                          "Ctrl-Space",
+		{Code: KeyLeft, Mod: ModAlt}:       "Alt-Left",
+		{Code: KeyEnter, Mod: ModCtrl}:     "Ctrl-Enter",
+		{Rune: 'x', Mod: ModAlt | ModCtrl}: "Ctrl-Alt-X",
+	} {
+		if s := k.String(); s != want {
+			t.Errorf("expected %#v got %#v", want, s)
+		}
+	}
+}
+
+func TestEditor_EscTimeout(t *testing.T) {
+	r, w := io.Pipe()
+	go func() {
+		w.Write([]byte("foo\x1b"))
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte("a\x1bb\r"))
+	}()
+
+	e := &Terminal{
+		Inp:        bufio.NewReader(r),
+		Out:        bufio.NewWriter(&bytes.Buffer{}),
+		Prompt:     "> ",
+		EscTimeout: 10 * time.Millisecond,
+	}
+	if err := e.Bind(Key{Code: KeyEscape}, "kill-whole-line"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Bind(Key{Rune: 'b', Mod: ModAlt}, "yank"); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "afoo" {
+		t.Errorf(`expected "afoo" got %#v`, l)
+	}
+}
+
+func TestEditor_readKeyPaste(t *testing.T) {
+	e := &Terminal{Inp: bufio.NewReader(strings.NewReader("\x1b[200~ls\r\x1b[201~x"))}
+	k, err := e.readKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k != (Key{Code: KeyPaste}) || e.pasted != "ls\r" {
+		t.Errorf("expected Paste with \"ls\\r\" got %v with %#v", k, e.pasted)
+	}
+}
+
+func TestEditor_readKeyPasteMaxLineLen(t *testing.T) {
+	for in, want := range map[string]string{
+		"\x1b[200~ab\x1b[201~x":                                     "ab",
+		"\x1b[200~abc\x1b[201~x":                                    "abc",
+		"\x1b[200~abcd\x1b[201~x":                                   "abc",
+		"\x1b[200~" + strings.Repeat("y\x1b[", 1000) + "\x1b[201~x": "y\x1b[",
+	} {
+		e := &Terminal{Inp: bufio.NewReader(strings.NewReader(in)), MaxLineLen: 3}
+		k, err := e.readKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k != (Key{Code: KeyPaste}) || e.pasted != want {
+			t.Errorf("%.20q: expected Paste with %q got %v with %q", in, want, k, e.pasted)
+		}
+		if k, err := e.readKey(); err != nil || k != (Key{Rune: 'x'}) {
+			t.Errorf("%.20q: expected x after the paste got %v, %v", in, k, err)
+		}
+	}
+}
+
+func TestEditor_readKeyLongSequence(t *testing.T) {
+	for _, in := range []string{
+		"\x1b[" + strings.Repeat("1;", maxCSI) + "C",
+		"\x1b]52;c;" + strings.Repeat("eA==", maxOSC/4) + "\a",
+		"\x1b]52;c;" + strings.Repeat("eA==", maxOSC/4) + "\x1b\\",
+	} {
+		e := &Terminal{Inp: bufio.NewReader(strings.NewReader(in + "x"))}
+		if k, err := e.readKey(); err != nil || k != (Key{Code: KeyUnknown}) {
+			t.Errorf("%.20q: expected Unknown got %v, %v", in, k, err)
+		}
+		if k, err := e.readKey(); err != nil || k != (Key{Rune: 'x'}) {
+			t.Errorf("%.20q: expected x after the sequence got %v, %v", in, k, err)
+		}
+	}
+}
+
+func TestEditor_LineKittyKeyboard(t *testing.T) {
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp:           bufio.NewReader(strings.NewReader("foo\x1b[13;5ubar\x1b[97;6u\x1b[27ux\r")),
+		Out:           bufio.NewWriter(&out),
+		Prompt:        "> ",
+		KittyKeyboard: true,
+	}
+	if err := e.Bind(Key{Code: KeyEnter, Mod: ModCtrl}, "kill-whole-line"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Bind(Key{Rune: 'a', Mod: ModCtrl | ModShift}, "beginning-of-line"); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "xbar" {
+		t.Errorf(`expected "xbar" got %#v`, l)
+	}
+	if s := out.String(); !strings.HasPrefix(s, "\x1b[>1u") || !strings.HasSuffix(s, "\x1b[<u") {
+		t.Errorf("expected the protocol switched on and off in %q", s)
+	}
+}
+
+func TestEditor_SeqTimeout(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		late    string
+		want    string
+	}{
+		{10 * time.Millisecond, "1;5Cb\r", "ab"}, // the stale "ESC [" is dropped, its late rest discarded
+		{10 * time.Millisecond, "Bb\r", "ab"},
+		{10 * time.Millisecond, "\x01b\r", "ba"}, // Ctrl-A can't be part of it, it's a key
+		{0, "\x01b\r", "a"},                      // Ctrl-A and b complete the sequence
+	}
+	for _, tt := range tests {
+		r, w := io.Pipe()
+		go func() {
+			w.Write([]byte("a\x1b["))
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte(tt.late))
+		}()
+
+		e := &Terminal{
+			Inp:        bufio.NewReader(r),
+			Out:        bufio.NewWriter(&bytes.Buffer{}),
+			Prompt:     "> ",
+			SeqTimeout: tt.timeout,
+		}
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Error(err)
+		}
+		if l != tt.want {
+			t.Errorf("%v %q: expected %q got %q", tt.timeout, tt.late, tt.want, l)
+		}
+	}
+}
+
+func TestEditor_SeqTimeoutSplit(t *testing.T) {
+	r, w := io.Pipe()
+	go func() {
+		w.Write([]byte("ab\x1b[1;5"))
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("Dx\r"))
+	}()
+
+	e := &Terminal{
+		Inp:        bufio.NewReader(r),
+		Out:        bufio.NewWriter(&bytes.Buffer{}),
+		Prompt:     "> ",
+		SeqTimeout: time.Second,
+	}
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "xab" {
+		t.Errorf(`expected "xab" got %#v`, l)
+	}
+}
@@ -0,0 +1,44 @@
+package linenoisy
+
+import "slices"
+
+const killRingSize = 16
+
+// kill pushes s to the kill ring for Ctrl-Y.
+func (e *Terminal) kill(s []rune) {
+	if len(s) == 0 || e.silent {
+		return
+	}
+	e.killRing = append(e.killRing, string(s))
+	if len(e.killRing) > killRingSize {
+		e.killRing = e.killRing[1:]
+	}
+	if e.Clipboard {
+		e.setClipboard(string(s)) // a write error fails the refresh after the kill as well
+	}
+}
+
+// editKillBackward kills the text from the beginning of the line to the cursor.
+func (e *Terminal) editKillBackward() error {
+	if e.Cur == 0 {
+		return e.beep()
+	}
+	e.kill(e.Buffer[:e.Cur])
+	e.Buffer = slices.Delete(e.Buffer, 0, e.Cur)
+	e.Cur = 0
+	return e.refreshLine()
+}
+
+// editKillWholeLine kills the line and starts over, like Ctrl-U did before it killed only up to the cursor.
+func (e *Terminal) editKillWholeLine() error {
+	e.kill(e.Buffer)
+	return e.LineReset()
+}
+
+// editYank inserts the most recently killed text at the cursor.
+func (e *Terminal) editYank() error {
+	if len(e.killRing) == 0 {
+		return e.beep()
+	}
+	return e.editInsertRunes([]rune(e.killRing[len(e.killRing)-1]))
+}
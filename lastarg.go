@@ -0,0 +1,46 @@
+package linenoisy
+
+import (
+	"slices"
+	"strings"
+)
+
+// lastArgYank is the text inserted by the previous yank-last-arg, which the next one replaces.
+type lastArgYank struct {
+	key   int // keyCount of the yank.
+	entry int // index of the history entry the argument came from.
+	start int
+	arg   []rune // the runes that went into Buffer, cut down under MaxLineLen.
+}
+
+// editYankLastArg inserts the last whitespace separated word of the previous history entry at the cursor.
+// Pressed again right after, it replaces that word by the last word of the entry before, like Alt-. in bash.
+func (e *Terminal) editYankLastArg() error {
+	lines := e.History.entries()
+	entry := len(lines) - 1 // the line being edited
+	prev := e.lastArg
+	e.lastArg = nil
+	if prev != nil && prev.key == e.keyCount-1 {
+		entry = prev.entry
+		e.Buffer = slices.Delete(e.Buffer, prev.start, prev.start+len(prev.arg))
+		e.Cur = prev.start
+	}
+
+	for entry--; entry >= 0; entry-- {
+		if f := strings.Fields(lines[entry]); len(f) > 0 {
+			arg := []rune(f[len(f)-1])
+			e.lastArg = &lastArgYank{key: e.keyCount, entry: entry, start: e.Cur, arg: arg[:e.room(len(arg))]}
+			return e.editInsertRunes(arg)
+		}
+	}
+	if prev != nil && prev.key == e.keyCount-1 {
+		// no older argument, keep the oldest one
+		e.lastArg = &lastArgYank{key: e.keyCount, entry: prev.entry, start: prev.start, arg: prev.arg}
+		e.Buffer = slices.Insert(e.Buffer, prev.start, prev.arg...)
+		e.Cur = prev.start + len(prev.arg)
+		if err := e.refreshLine(); err != nil {
+			return err
+		}
+	}
+	return e.beep()
+}
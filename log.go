@@ -0,0 +1,31 @@
+package linenoisy
+
+import (
+	"io"
+	"log/slog"
+)
+
+// LogWriter returns an io.Writer printing above the edit region through WriteOut, for log.New:
+//
+//	log.SetOutput(e.LogWriter())
+func (e *Terminal) LogWriter() io.Writer {
+	return logWriter{e: e}
+}
+
+type logWriter struct {
+	e *Terminal
+}
+
+func (w logWriter) Write(b []byte) (int, error) {
+	return w.e.WriteOut(b)
+}
+
+// LogHandler returns a slog.Handler formatting records like slog.TextHandler and printing them above the edit region,
+// so background logging doesn't break into the line being typed:
+//
+//	slog.SetDefault(slog.New(e.LogHandler(nil)))
+//
+// Like WriteOut, it must not be used from the callbacks of LineEditor.
+func (e *Terminal) LogHandler(opts *slog.HandlerOptions) slog.Handler {
+	return slog.NewTextHandler(e.LogWriter(), opts)
+}
@@ -0,0 +1,52 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"log"
+	"log/slog"
+	"testing"
+)
+
+func TestEditor_LogHandler(t *testing.T) {
+	out := &checkedWriter{
+		expectations: []string{
+			"\r\x1b[0Klevel=INFO msg=connected user=joe\r\n",
+			"\r> ls\x1b[0K\r\x1b[4C",
+		},
+	}
+	e := &Terminal{
+		Inp:    bufio.NewReader(&bytes.Buffer{}),
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+		Buffer: []rune("ls"),
+		Cur:    2,
+	}
+
+	l := slog.New(e.LogHandler(&slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	l.Info("connected", "user", "joe")
+}
+
+func TestEditor_LogWriter(t *testing.T) {
+	out := &checkedWriter{
+		expectations: []string{
+			"\r\x1b[0Kserver: connected\r\n",
+			"\r> \x1b[0K\r\x1b[2C",
+		},
+	}
+	e := &Terminal{
+		Inp:    bufio.NewReader(&bytes.Buffer{}),
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+	}
+
+	l := log.New(e.LogWriter(), "server: ", 0)
+	l.Print("connected")
+}
@@ -0,0 +1,71 @@
+package linenoisy
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics counts the work of the terminals it is set on. It is safe for concurrent use, so the terminals
+// of all sessions of a server may share one for totals.
+type Metrics struct {
+	keys, refreshes, bytes, completions, completionTime atomic.Int64
+}
+
+// MetricsSnapshot are the counters of Metrics at one point. It suits expvar:
+//
+//	expvar.Publish("editor", expvar.Func(func() any { return m.Snapshot() }))
+type MetricsSnapshot struct {
+	Keys           int64         // keys handled by LineEditor.
+	Refreshes      int64         // redraws of the edit region.
+	BytesWritten   int64         // output written to the transport, after the middlewares.
+	Completions    int64         // calls of the completion functions.
+	CompletionTime time.Duration // spent in the completion functions in total.
+}
+
+// Snapshot returns the current counters.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	return MetricsSnapshot{
+		Keys:           m.keys.Load(),
+		Refreshes:      m.refreshes.Load(),
+		BytesWritten:   m.bytes.Load(),
+		Completions:    m.completions.Load(),
+		CompletionTime: time.Duration(m.completionTime.Load()),
+	}
+}
+
+// completed counts a completion call which started at start.
+func (m *Metrics) completed(start time.Time) {
+	m.completions.Add(1)
+	m.completionTime.Add(int64(time.Since(start)))
+}
+
+// countOutput puts a counter of the bytes written between Out and the transport, once.
+// Without a transport, like a Terminal built around a plain Out, the bytes aren't counted.
+func (e *Terminal) countOutput() error {
+	if e.counted {
+		return nil
+	}
+	w := e.transport()
+	if w == nil {
+		return nil
+	}
+	if err := e.Out.Flush(); err != nil {
+		return err
+	}
+	e.out = byteCounter{w: w, m: e.Metrics}
+	e.Out.Reset(e.out)
+	e.counted = true
+	return nil
+}
+
+type byteCounter struct {
+	w io.Writer
+	m *Metrics
+}
+
+func (c byteCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.m.bytes.Add(int64(n))
+	return n, err
+}
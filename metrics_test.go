@@ -0,0 +1,28 @@
+package linenoisy
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEditor_Metrics(t *testing.T) {
+	ch := &rwc{Reader: bytes.NewBufferString("gi\t\r")}
+	e := NewTerminal(ch, "> ")
+	e.Complete = func(line string) []string { return []string{"git"} }
+	e.Metrics = &Metrics{}
+
+	if _, err := e.LineEditor(); err != nil {
+		t.Fatal(err)
+	}
+
+	m := e.Metrics.Snapshot()
+	if m.Keys != 4 || m.Completions != 1 {
+		t.Errorf("expected 4 keys and 1 completion got %+v", m)
+	}
+	if m.Refreshes != 4 { // the empty line, g, i and the completion
+		t.Errorf("expected 4 refreshes got %d", m.Refreshes)
+	}
+	if m.BytesWritten != int64(ch.Len()) {
+		t.Errorf("expected %d bytes got %d", ch.Len(), m.BytesWritten)
+	}
+}
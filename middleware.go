@@ -0,0 +1,42 @@
+package linenoisy
+
+import (
+	"errors"
+	"io"
+)
+
+// Middleware decorates the writer which carries editor output to the transport
+// (recording, CRLF policy, IAC escaping, rate limiting, ...).
+type Middleware func(io.Writer) io.Writer
+
+// Use stacks output middlewares between Out and the transport.
+// The first middleware receives the editor output first; every call of Use
+// stacks its middlewares on top of those installed before.
+func (e *Terminal) Use(mw ...Middleware) error {
+	if err := e.Out.Flush(); err != nil {
+		return err
+	}
+
+	w := e.transport()
+	if w == nil {
+		return errors.New("no transport to install middleware on")
+	}
+	for i := len(mw) - 1; i >= 0; i-- {
+		w = mw[i](w)
+	}
+
+	e.out = w
+	e.Out.Reset(w)
+	return nil
+}
+
+// transport returns the writer beneath Out.
+func (e *Terminal) transport() io.Writer {
+	if e.out != nil {
+		return e.out
+	}
+	if e.Raw != nil {
+		return e.Raw
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package linenoisy
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestTerminal_Use(t *testing.T) {
+	ch := &rwc{Reader: bytes.NewBuffer(nil)}
+	e := NewTerminal(ch, "> ")
+
+	var order []string
+	tag := func(name string) Middleware {
+		return func(w io.Writer) io.Writer {
+			return writerFunc(func(p []byte) (int, error) {
+				order = append(order, name)
+				return w.Write(p)
+			})
+		}
+	}
+
+	if err := e.Use(tag("a"), tag("b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Use(tag("c")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.LineReset(); err != nil {
+		t.Fatal(err)
+	}
+	if got := ch.String(); got != "\r> \x1b[0K\r\x1b[2C" {
+		t.Errorf(`expected "\r> \x1b[0K\r\x1b[2C" got %#v`, got)
+	}
+	if want := []string{"c", "a", "b"}; !slices.Equal(order, want) {
+		t.Errorf("expected %v got %v", want, order)
+	}
+
+	order = nil
+	if _, err := e.Write([]byte("x\n")); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"c", "a", "b", "c", "a", "b"}; !slices.Equal(order, want) {
+		t.Errorf("expected %v got %v", want, order)
+	}
+}
+
+type rwc struct {
+	io.Reader
+	bytes.Buffer
+}
+
+func (c *rwc) Read(p []byte) (int, error)  { return c.Reader.Read(p) }
+func (c *rwc) Write(p []byte) (int, error) { return c.Buffer.Write(p) }
+func (c *rwc) Close() error                { return nil }
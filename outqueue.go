@@ -0,0 +1,57 @@
+package linenoisy
+
+import (
+	"bytes"
+	"sync"
+)
+
+// outQueue collects the output of WriteOut calls waiting for the edit lock,
+// so a burst is printed by a single clear/print/refresh cycle.
+type outQueue struct {
+	mu      sync.Mutex
+	buf     []byte
+	queued  uint64 // number of the last pushed output.
+	printed uint64 // number of the last output taken for printing.
+	err     error  // result of the last printing cycle.
+}
+
+// push appends b, with terminal line endings, and returns its number.
+func (q *outQueue) push(b []byte) uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.buf = append(q.buf, bytes.ReplaceAll(b, []byte("\n"), []byte("\r\n"))...)
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		q.buf = append(q.buf, '\r', '\n')
+	}
+	q.queued++
+	return q.queued
+}
+
+// take returns everything queued, or false if output n was already taken by an earlier cycle.
+func (q *outQueue) take(n uint64) ([]byte, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if n <= q.printed {
+		return nil, false
+	}
+	b := q.buf
+	q.buf = nil
+	q.printed = q.queued
+	return b, true
+}
+
+func (q *outQueue) setErr(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.err = err
+}
+
+func (q *outQueue) lastErr() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.err
+}
@@ -0,0 +1,65 @@
+package linenoisy
+
+import "fmt"
+
+// pageColumns writes the rows like writeColumns, but a listing taller than the screen
+// is only shown after the user confirmed it, one screen at a time:
+// Space or PageDown shows the next screen, Enter the next row, q stops.
+func (e *Terminal) pageColumns(ew *errWriter, indent string, rows [][]string, padding, total int) error {
+	e.notZero()
+	if len(rows) < e.Rows {
+		e.writeColumns(ew, indent, rows, padding)
+		return ew.err
+	}
+
+	e.leaveRegion()
+	ew.writeString(fmt.Sprintf("\n\rDisplay all %d possibilities? (y/n)", total))
+	ew.flush()
+	for {
+		k, err := e.readKey()
+		if err != nil {
+			return err
+		}
+		switch k {
+		case Key{Rune: 'y'}, Key{Rune: 'Y'}, Key{Rune: ' '}:
+		case Key{Rune: 'n'}, Key{Rune: 'N'}, Key{Rune: 'q'}, ctrl('c'), ctrl('g'):
+			ew.writeString("\n")
+			return ew.err
+		default:
+			continue
+		}
+		break
+	}
+
+	colw := e.columnWidths(rows)
+	page := e.Rows - 1
+	for i := 0; i < len(rows); {
+		for end := min(i+page, len(rows)); i < end; i++ {
+			e.writeRow(ew, indent, rows[i], colw, padding)
+		}
+		if i == len(rows) {
+			break
+		}
+
+		ew.writeString("\n\r--More--")
+		ew.flush()
+		if ew.err != nil {
+			return ew.err
+		}
+		k, err := e.readKey()
+		if err != nil {
+			return err
+		}
+		ew.writeString("\r" + e.caps().ClearEOL + e.caps().up(1)) // erase --More--, the next row starts with a line feed
+		switch k {
+		case Key{Rune: ' '}, Key{Rune: 'y'}, Key{Code: KeyPageDown}:
+			page = e.Rows - 1
+		case Key{Code: KeyEnter}:
+			page = 1
+		default:
+			i = len(rows)
+		}
+	}
+	ew.writeString("\n")
+	return ew.err
+}
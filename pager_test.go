@@ -0,0 +1,65 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Joker/linenoisy/vtest"
+)
+
+func TestEditor_LineTabPager(t *testing.T) {
+	var opts []string
+	for i := range 10 {
+		opts = append(opts, fmt.Sprintf("candidate%d", i))
+	}
+
+	tests := []struct {
+		name string
+		keys string
+		want []string
+	}{
+		{"declined", "n", []string{"> c", "Display all 10 possibilities? (y/n)", "> c"}},
+		{"quit", "y q", []string{
+			"> c",
+			"Display all 10 possibilities? (y/n)",
+			"    candidate0", "    candidate1", "    candidate2", "    candidate3",
+			"    candidate4", "    candidate5", "    candidate6", "    candidate7",
+			"> c",
+		}},
+		{"enter", "y\x0dq", []string{
+			"> c",
+			"Display all 10 possibilities? (y/n)",
+			"    candidate0", "    candidate1", "    candidate2", "    candidate3",
+			"    candidate4",
+			"> c",
+		}},
+		{"all", "y  ", []string{
+			"> c",
+			"Display all 10 possibilities? (y/n)",
+			"    candidate0", "    candidate1", "    candidate2", "    candidate3",
+			"    candidate4", "    candidate5", "    candidate6", "    candidate7",
+			"    candidate8", "    candidate9",
+			"> c",
+		}},
+	}
+	for _, tt := range tests {
+		s := vtest.New(40, 30) // wider than Cols, so the question stays on one row
+		e := &Terminal{
+			Inp:      bufio.NewReader(bytes.NewBufferString("c\t" + tt.keys)),
+			Out:      bufio.NewWriter(s),
+			Prompt:   "> ",
+			Cols:     20,
+			Rows:     5,
+			Complete: func(string) []string { return opts },
+		}
+		e.LineEditor()
+
+		got := slices.DeleteFunc(s.Lines(), func(l string) bool { return l == "" })
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %q got %q", tt.name, tt.want, got)
+		}
+	}
+}
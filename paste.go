@@ -0,0 +1,90 @@
+package linenoisy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// readPaste reads bracketed paste content up to `ESC [ 201 ~`. Under MaxLineLen only the first MaxLineLen runes
+// are kept, the rest is read and discarded, so a client can't fill the memory with one paste.
+func (e *Terminal) readPaste() (string, error) {
+	end := []rune("\x1b[201~")
+
+	var rs []rune
+	for len(rs) < len(end) || !slices.Equal(rs[len(rs)-len(end):], end) {
+		r, _, err := e.Inp.ReadRune()
+		if err != nil {
+			return string(rs), err
+		}
+		if e.MaxLineLen > 0 && len(rs) == e.MaxLineLen+len(end) { // keep just enough of the rest to find the end
+			rs = slices.Delete(rs, e.MaxLineLen, e.MaxLineLen+1)
+		}
+		rs = append(rs, r)
+	}
+	return string(rs[:len(rs)-len(end)]), nil
+}
+
+// editPaste inserts the text of a KeyPaste at once. Text of several lines is only inserted, joined into one line,
+// after the user confirms it, so a pasted script is never executed by accident.
+func (e *Terminal) editPaste() error {
+	s := e.pasted
+	e.pasted = ""
+
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	s = sanitizePaste(s)
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if len(lines) == 1 {
+		return e.editInsertRunes([]rune(lines[0]))
+	}
+
+	e.leaveRegion()
+	ew := &errWriter{w: e.Out}
+	ew.writeString(fmt.Sprintf("\n\rpaste contains %d lines - [j]oin into one line, [c]ancel? ", len(lines)))
+	ew.flush()
+	if ew.err != nil {
+		return ew.err
+	}
+
+	k, err := e.readKey()
+	if err != nil {
+		return err
+	}
+	ew.writeString("\n")
+	if ew.err != nil {
+		return ew.err
+	}
+
+	if k.Code == KeyRune && k.Mod == 0 && strings.ContainsRune("jJyY", k.Rune) {
+		return e.editInsertRunes([]rune(strings.Join(lines, " ")))
+	}
+	return e.refreshLine()
+}
+
+// sanitizePaste takes escape sequences and C1 controls out of pasted text and turns the other control
+// characters, but tab and newline, into caret notation, so a paste can't send commands to the terminal
+// when it's echoed.
+func sanitizePaste(s string) string {
+	rs := []rune(s)
+	var sb strings.Builder
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case r == esc:
+			i = escapeEnd(rs, i)
+			continue
+		case r == '\t', r == '\n':
+			sb.WriteRune(r)
+		case r < ' ':
+			sb.WriteString("^" + string(r+'@'))
+		case r == backspace:
+			sb.WriteString("^?")
+		case r >= 0x80 && r < 0xa0:
+		default:
+			sb.WriteRune(r)
+		}
+		i++
+	}
+	return sb.String()
+}
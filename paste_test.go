@@ -0,0 +1,97 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestEditor_LineBracketedPaste(t *testing.T) {
+	in := bytes.NewBuffer([]byte("\x1b[200~foo\x1b[201~\x1b[200~ bar\r\nbaz\n\x1b[201~j\x0d"))
+	out := &checkedWriter{
+		expectations: []string{
+			"\x1b[?2004h",
+			"\r> \x1b[0K\r\x1b[2C",
+			"\r> foo\x1b[0K\r\x1b[5C",
+			"\n\rpaste contains 2 lines - [j]oin into one line, [c]ancel? ",
+			"\n\r> foo bar baz\x1b[0K\r\x1b[13C",
+			"\x1b[?2004l",
+		},
+	}
+
+	e := &Terminal{
+		Inp:            bufio.NewReader(in),
+		Out:            bufio.NewWriter(out),
+		Prompt:         "> ",
+		BracketedPaste: true,
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "foo bar baz" {
+		t.Errorf(`expected "foo bar baz" got %#v`, l)
+	}
+}
+
+func TestEditor_LineBracketedPasteCancel(t *testing.T) {
+	in := bytes.NewBuffer([]byte("\x1b[200~rm -rf /\nreboot\x1b[201~c\x0d"))
+	out := &checkedWriter{
+		expectations: []string{
+			"\r> \x1b[0K\r\x1b[2C",
+			"\n\rpaste contains 2 lines - [j]oin into one line, [c]ancel? ",
+			"\n\r> \x1b[0K\r\x1b[2C",
+		},
+	}
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "" {
+		t.Errorf(`expected "" got %#v`, l)
+	}
+}
+
+func TestSanitizePaste(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ls -l\tfoo\n", "ls -l\tfoo\n"},
+		{"echo \x1b]2;pwned\a!", "echo !"},
+		{"a\x1b[2Jb", "ab"},
+		{"a\x07b\x7fc", "a^Gb^?c"},
+		{"a\u009b31mb", "a31mb"},
+		{"日本\x00", "日本^@"},
+	}
+	for _, tt := range tests {
+		if got := sanitizePaste(tt.in); got != tt.want {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestEditor_LineBracketedPasteControls(t *testing.T) {
+	in := bytes.NewBuffer([]byte("\x1b[200~ls\x1b[2J\x03\x1b[201~\x0d"))
+	e := &Terminal{
+		Inp:            bufio.NewReader(in),
+		Out:            bufio.NewWriter(&bytes.Buffer{}),
+		Prompt:         "> ",
+		BracketedPaste: true,
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Error(err)
+	}
+	if l != "ls^C" {
+		t.Errorf(`expected "ls^C" got %#v`, l)
+	}
+}
@@ -0,0 +1,119 @@
+package linenoisy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Features are the capabilities of the terminal found by Probe.
+type Features struct {
+	Attributes     []int // the parameters of the DA1 answer, e.g. 62 (VT220) and 22 (ANSI color).
+	Color          bool  // DA1 reports ANSI color, or truecolor was found.
+	TrueColor      bool  // a 24 bit color was kept, asked with DECRQSS.
+	Unicode        bool  // a UTF-8 encoded character advanced the cursor by one column.
+	BracketedPaste bool  // mode 2004 is known to DECRQM.
+	Mouse          bool  // mode 1006, SGR mouse reports, is known to DECRQM.
+	KittyKeyboard  bool  // the flags of the kitty keyboard protocol were reported.
+	AutoWrap       bool  // DECAWM isn't reset.
+}
+
+var (
+	da1Pattern    = regexp.MustCompile("\x1b\\[\\?([\\d;]*)c")
+	kittyPattern  = regexp.MustCompile("\x1b\\[\\?\\d+u")
+	decrqmPattern = regexp.MustCompile("\x1b\\[\\?(\\d+);(\\d)\\$y")
+	decrqssColor  = regexp.MustCompile("\x1bP1\\$r[^\x1b]*38[:;]2[:;]+1[:;]2[:;]3")
+)
+
+// probeQueries ask for the modes and the keyboard protocol flags, then the column before and after an 'é', the SGR
+// after setting a 24 bit color and last for the device attributes, which every VT100 compatible terminal answers.
+const probeQueries = "\x1b[?2004$p\x1b[?1006$p\x1b[?7$p\x1b[?u" +
+	"\x1b7\x1b[6né\x1b[6n\x1b8\x1b[0K" +
+	"\x1b[38;2;1;2;3m\x1bP$qm\x1b\\\x1b[0m" +
+	"\x1b[c"
+
+// Probe asks the terminal about its capabilities and keeps the answers in Features, which
+// BracketedPaste, Mouse, KittyKeyboard, Foreground and the rendering consult. The queries a terminal doesn't know
+// go unanswered; like Adjust, Probe waits until the terminal answers DA1 and is called before LineEditor.
+func (e *Terminal) Probe() error {
+	if _, err := e.Out.WriteString(probeQueries); err != nil {
+		return err
+	}
+	if err := e.Out.Flush(); err != nil {
+		return err
+	}
+
+	var res strings.Builder
+	for !da1Pattern.MatchString(res.String()) {
+		s, err := e.readReply('c')
+		res.WriteString(s)
+		if err != nil {
+			return err
+		}
+	}
+	e.Features = parseFeatures(res.String())
+	return nil
+}
+
+func parseFeatures(res string) *Features {
+	f := &Features{AutoWrap: true}
+
+	for _, p := range strings.Split(da1Pattern.FindStringSubmatch(res)[1], ";") {
+		if n, err := strconv.Atoi(p); err == nil {
+			f.Attributes = append(f.Attributes, n)
+			f.Color = f.Color || n == 22
+		}
+	}
+	for _, m := range decrqmPattern.FindAllStringSubmatch(res, -1) {
+		known := m[2] == "1" || m[2] == "2" || m[2] == "3" // set, reset or permanently set
+		switch m[1] {
+		case "2004":
+			f.BracketedPaste = known
+		case "1006":
+			f.Mouse = known
+		case "7":
+			f.AutoWrap = m[2] != "2" && m[2] != "4"
+		}
+	}
+	if ps := curPosPattern.FindAllStringSubmatch(res, 2); len(ps) == 2 {
+		before, _ := strconv.Atoi(ps[0][2])
+		after, _ := strconv.Atoi(ps[1][2])
+		f.Unicode = after-before == 1
+	}
+	f.KittyKeyboard = kittyPattern.MatchString(res)
+	if decrqssColor.MatchString(res) {
+		f.TrueColor, f.Color = true, true
+	}
+	return f
+}
+
+// bracketedPaste reports whether BracketedPaste is on and not ruled out by Probe.
+func (e *Terminal) bracketedPaste() bool {
+	return e.BracketedPaste && (e.Features == nil || e.Features.BracketedPaste)
+}
+
+// mouse reports whether Mouse is on and not ruled out by Probe.
+func (e *Terminal) mouse() bool {
+	return e.Mouse && (e.Features == nil || e.Features.Mouse)
+}
+
+// kittyKeyboard reports whether KittyKeyboard is on and not ruled out by Probe.
+func (e *Terminal) kittyKeyboard() bool {
+	return e.KittyKeyboard && (e.Features == nil || e.Features.KittyKeyboard)
+}
+
+// Foreground returns the SGR sequence of the 24 bit color r, g, b, or of the closest of the eight
+// standard colors if Probe found the terminal without truecolor.
+func (e *Terminal) Foreground(r, g, b uint8) []byte {
+	if e.Features == nil || e.Features.TrueColor {
+		return fmt.Appendf(nil, "\x1b[38;2;%d;%d;%dm", r, g, b)
+	}
+	c := 0
+	for i, v := range []uint8{r, g, b} {
+		if v >= 128 {
+			c |= 1 << i
+		}
+	}
+	return []byte{esc, '[', '3', byte('0' + c), 'm'}
+}
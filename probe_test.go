@@ -0,0 +1,88 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEditor_Probe(t *testing.T) {
+	tests := []struct {
+		name, answers string
+		want          Features
+	}{
+		{
+			"xterm",
+			"\x1b[?2004;2$y\x1b[?1006;2$y\x1b[?7;1$y\x1b[?0u\x1b[3;5R\x1b[3;6R\x1bP1$r0;38:2::1:2:3m\x1b\\\x1b[?64;1;22c",
+			Features{Attributes: []int{64, 1, 22}, Color: true, TrueColor: true, Unicode: true, BracketedPaste: true, Mouse: true, KittyKeyboard: true, AutoWrap: true},
+		},
+		{
+			"vt100",
+			"\x1b[3;5R\x1b[3;7R\x1b[?1;2c",
+			Features{Attributes: []int{1, 2}, AutoWrap: true},
+		},
+		{
+			"serial console",
+			"\x1b[?2004;0$y\x1b[?1006;0$y\x1b[?7;2$y\x1bP0$r\x1b\\\x1b[?62;22c",
+			Features{Attributes: []int{62, 22}, Color: true},
+		},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		e := &Terminal{
+			Inp: bufio.NewReader(strings.NewReader(tt.answers)),
+			Out: bufio.NewWriter(&out),
+		}
+		if err := e.Probe(); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != probeQueries {
+			t.Errorf("%s: expected %q got %q", tt.name, probeQueries, out.String())
+		}
+		if !reflect.DeepEqual(*e.Features, tt.want) {
+			t.Errorf("%s: expected %+v got %+v", tt.name, tt.want, *e.Features)
+		}
+	}
+}
+
+func TestEditor_LineProbedModes(t *testing.T) {
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp:            bufio.NewReader(strings.NewReader("\x1b[?2004;2$y\x1b[?1;2cok\r")),
+		Out:            bufio.NewWriter(&out),
+		Prompt:         "> ",
+		BracketedPaste: true,
+		Mouse:          true,
+	}
+	if err := e.Probe(); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "ok" {
+		t.Errorf(`expected "ok" got %#v`, l)
+	}
+	if !strings.Contains(out.String(), "\x1b[?2004h") || strings.Contains(out.String(), "\x1b[?1000h") {
+		t.Errorf("expected bracketed paste and no mouse in %q", out.String())
+	}
+}
+
+func TestEditor_Foreground(t *testing.T) {
+	e := &Terminal{}
+	if got, want := string(e.Foreground(255, 128, 0)), "\x1b[38;2;255;128;0m"; got != want {
+		t.Errorf("expected %q got %q", want, got)
+	}
+	e.Features = &Features{Color: true}
+	if got, want := string(e.Foreground(255, 128, 0)), string(Yellow); got != want {
+		t.Errorf("expected %q got %q", want, got)
+	}
+	if got, want := string(e.Foreground(0, 0, 200)), string(Blue); got != want {
+		t.Errorf("expected %q got %q", want, got)
+	}
+}
@@ -0,0 +1,87 @@
+// Package prompt builds styled prompts for the line editor:
+//
+//	e.Prompt = prompt.New().Text("host").Fg(prompt.Cyan).Text(" > ").String()
+//
+// The styles and links are escape sequences which the editor skips when it measures the prompt,
+// so the cursor stays in place whatever the prompt is made of.
+package prompt
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Color is one of the eight standard terminal colors.
+type Color int
+
+const (
+	Black Color = iota
+	Red
+	Green
+	Yellow
+	Blue
+	Magenta
+	Cyan
+	White
+)
+
+// Builder collects the parts of a prompt. Styles apply to the text added after them, until Reset.
+type Builder struct {
+	sb     strings.Builder
+	styled bool
+}
+
+// New returns an empty prompt.
+func New() *Builder {
+	return &Builder{}
+}
+
+// Text adds s as it is shown; escape characters are dropped, styles come from the other methods.
+func (b *Builder) Text(s string) *Builder {
+	b.sb.WriteString(strings.ReplaceAll(s, "\x1b", ""))
+	return b
+}
+
+// Fg sets the foreground color.
+func (b *Builder) Fg(c Color) *Builder {
+	return b.sgr(30 + int(c))
+}
+
+// Bg sets the background color.
+func (b *Builder) Bg(c Color) *Builder {
+	return b.sgr(40 + int(c))
+}
+
+// Bold makes the following text bold.
+func (b *Builder) Bold() *Builder {
+	return b.sgr(1)
+}
+
+// Reset returns to the default style.
+func (b *Builder) Reset() *Builder {
+	b.sb.WriteString("\x1b[0m")
+	b.styled = false
+	return b
+}
+
+// Link adds text as a hyperlink to url (OSC 8), terminals without hyperlinks show the text only.
+func (b *Builder) Link(url, text string) *Builder {
+	b.sb.WriteString("\x1b]8;;" + url + "\x1b\\")
+	b.Text(text)
+	b.sb.WriteString("\x1b]8;;\x1b\\")
+	return b
+}
+
+// String returns the prompt, reset to the default style at its end so the line isn't colored.
+func (b *Builder) String() string {
+	if b.styled {
+		return b.sb.String() + "\x1b[0m"
+	}
+	return b.sb.String()
+}
+
+func (b *Builder) sgr(n int) *Builder {
+	b.sb.WriteString("\x1b[" + strconv.Itoa(n) + "m")
+	b.styled = true
+	return b
+}
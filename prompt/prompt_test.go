@@ -0,0 +1,19 @@
+package prompt
+
+import "testing"
+
+func TestBuilder(t *testing.T) {
+	for _, tt := range []struct {
+		b    *Builder
+		want string
+	}{
+		{New().Text("host").Fg(Cyan).Text(" > "), "host\x1b[36m > \x1b[0m"},
+		{New().Bold().Bg(Red).Text("!").Reset().Text(" "), "\x1b[1m\x1b[41m!\x1b[0m "},
+		{New().Link("https://example.com", "docs").Text("> "), "\x1b]8;;https://example.com\x1b\\docs\x1b]8;;\x1b\\> "},
+		{New().Text("a\x1b[2Jb"), "a[2Jb"},
+	} {
+		if got := tt.b.String(); got != tt.want {
+			t.Errorf("expected %q got %q", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package linenoisy
+
+import "strings"
+
+// promptLines splits Prompt into the lines drawn above the input and the last line the input follows.
+func (e *Terminal) promptLines() (head []string, last string) {
+	lines := strings.Split(e.Prompt, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	return lines[:len(lines)-1], lines[len(lines)-1]
+}
+
+// promptWidth returns the width of the last line of Prompt, where the input starts.
+func (e *Terminal) promptWidth() int {
+	_, last := e.promptLines()
+	return e.width(last)
+}
+
+// writePrompt writes Prompt from the beginning of a row, the lines above the input each on rows of their own,
+// and returns how many rows those take.
+func (e *Terminal) writePrompt(ew *errWriter) int {
+	e.notZero()
+	head, last := e.promptLines()
+	rows := 0
+	for _, l := range head {
+		ew.writeString(l)
+		w := e.width(l)
+		if w == 0 || w%e.Cols != 0 {
+			ew.writeString(e.caps().ClearEOL) // at the right edge it would take the last character away
+		}
+		ew.writeString("\r\n")
+		rows += max(1, (w+e.Cols-1)/e.Cols)
+	}
+	ew.writeString(last)
+	return rows
+}
@@ -0,0 +1,57 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/Joker/linenoisy/vtest"
+)
+
+func TestEditor_LineMultiLinePrompt(t *testing.T) {
+	s := vtest.New(10, 12)
+	e := &Terminal{
+		Inp:    bufio.NewReader(bytes.NewBufferString("abcdefghijklmn\x02\x02\x1bhxy\x08")),
+		Out:    bufio.NewWriter(s),
+		Prompt: "~/src/linenoisy\n> ",
+		Cols:   10,
+		Rows:   12,
+		Help: func(string) [][2]string {
+			return [][2]string{{"a", "b"}}
+		},
+	}
+
+	if _, err := e.LineEditor(); err != io.EOF {
+		t.Fatalf("expected EOF got %v", err)
+	}
+	want := "~/src/line\nnoisy\n> abcdefgh\nijklmn\n  a   b\n~/src/line\nnoisy\n> abcdefgh\nijklxmn"
+	if s.String() != want {
+		t.Errorf("expected %q got %q", want, s.String())
+	}
+	if col, row := s.Cursor(); col != 5 || row != 8 {
+		t.Errorf("expected cursor 5,8 got %d,%d", col, row)
+	}
+}
+
+func TestEditor_LineMultiLineContPrompt(t *testing.T) {
+	s := vtest.New(10, 6)
+	e := &Terminal{
+		Inp:        bufio.NewReader(bytes.NewBufferString("abcdefghijkl\x7f\x7f\x7f\x7f\x7f")),
+		Out:        bufio.NewWriter(s),
+		Prompt:     "[main]\n> ",
+		ContPrompt: ". ",
+		Cols:       10,
+		Rows:       6,
+	}
+
+	if _, err := e.LineEditor(); err != io.EOF {
+		t.Fatalf("expected EOF got %v", err)
+	}
+	if want := "[main]\n> abcdefg"; s.String() != want {
+		t.Errorf("expected %q got %q", want, s.String())
+	}
+	if col, row := s.Cursor(); col != 9 || row != 1 {
+		t.Errorf("expected cursor 9,1 got %d,%d", col, row)
+	}
+}
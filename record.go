@@ -0,0 +1,155 @@
+package linenoisy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Recorder writes a session with its timing into an asciinema v2 (asciicast) file
+// or a script(1) typescript with a scriptreplay timing file.
+type Recorder struct {
+	mu    sync.Mutex
+	start time.Time
+	last  time.Time
+	err   error
+
+	partial map[string][]byte // the incomplete rune at the end of the last chunk of each asciicast event type.
+
+	cast   io.Writer // asciicast events.
+	script io.Writer // typescript data.
+	timing io.Writer // typescript timing.
+}
+
+// NewAsciicast writes the asciicast header for a cols x rows terminal to w and returns a Recorder of events into w.
+// https://docs.asciinema.org/manual/asciicast/v2/
+func NewAsciicast(w io.Writer, cols, rows int) (*Recorder, error) {
+	now := time.Now()
+	header, err := json.Marshal(struct {
+		Version   int   `json:"version"`
+		Width     int   `json:"width"`
+		Height    int   `json:"height"`
+		Timestamp int64 `json:"timestamp"`
+	}{2, cols, rows, now.Unix()})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", header); err != nil {
+		return nil, err
+	}
+	return &Recorder{start: now, last: now, cast: w}, nil
+}
+
+// NewTypescript writes the output into script and the delays for `scriptreplay timing script` into timing.
+// The typescript format has no place for keystrokes, so recorded input is dropped.
+func NewTypescript(script, timing io.Writer) (*Recorder, error) {
+	now := time.Now()
+	if _, err := fmt.Fprintf(script, "Script started on %s\n", now.Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+	return &Recorder{start: now, last: now, script: script, timing: timing}, nil
+}
+
+// Output returns a Middleware which tees the editor output into the recording.
+func (r *Recorder) Output() Middleware {
+	return func(w io.Writer) io.Writer {
+		return writerFunc(func(p []byte) (int, error) {
+			n, err := w.Write(p)
+			r.record("o", p[:n])
+			return n, err
+		})
+	}
+}
+
+// Input returns a reader which tees what is read from rd as keystrokes into the recording.
+func (r *Recorder) Input(rd io.Reader) io.Reader {
+	return readerFunc(func(p []byte) (int, error) {
+		n, err := rd.Read(p)
+		r.record("i", p[:n])
+		return n, err
+	})
+}
+
+// Err returns the first error of writing the recording.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+func (r *Recorder) record(typ string, p []byte) {
+	if len(p) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case r.cast != nil:
+		// JSON strings hold whole runes, a rune cut by the chunk boundary waits for the next chunk
+		p = append(r.partial[typ], p...)
+		n := len(p) - incompleteRune(p)
+		if r.partial == nil {
+			r.partial = map[string][]byte{}
+		}
+		r.partial[typ] = append([]byte(nil), p[n:]...)
+		if n == 0 {
+			return
+		}
+		ev, err := json.Marshal([]any{now.Sub(r.start).Seconds(), typ, string(p[:n])})
+		if err != nil {
+			r.err = err
+			return
+		}
+		_, r.err = fmt.Fprintf(r.cast, "%s\n", ev)
+	case typ == "o":
+		if _, r.err = fmt.Fprintf(r.timing, "%.6f %d\n", now.Sub(r.last).Seconds(), len(p)); r.err != nil {
+			return
+		}
+		_, r.err = r.script.Write(p)
+		r.last = now // the delays are between the written chunks
+	}
+}
+
+// incompleteRune returns the length of the beginning of a rune at the end of p, 0 if p ends with a whole one.
+func incompleteRune(p []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(p); i++ {
+		if utf8.RuneStart(p[len(p)-i]) {
+			if utf8.FullRune(p[len(p)-i:]) {
+				return 0
+			}
+			return i
+		}
+	}
+	return 0
+}
+
+// Record tees the output, and the keystrokes if input is set, of the Terminal into r.
+func (e *Terminal) Record(r *Recorder, input bool) error {
+	if err := e.Use(r.Output()); err != nil {
+		return err
+	}
+	if input {
+		e.Inp = bufio.NewReader(r.Input(e.Inp))
+	}
+	return nil
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
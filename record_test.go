@@ -0,0 +1,165 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTerminal_RecordAsciicast(t *testing.T) {
+	var cast bytes.Buffer
+	rec, err := NewAsciicast(&cast, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch := &rwc{Reader: bytes.NewBufferString("a\x0d")}
+	e := NewTerminal(ch, "> ")
+	if err := e.Record(rec, true); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "a" {
+		t.Errorf(`expected "a" got %#v`, l)
+	}
+	if err := rec.Err(); err != nil {
+		t.Error(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(cast.String(), "\n"), "\n")
+	var header map[string]int
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header["version"] != 2 || header["width"] != 80 || header["height"] != 24 {
+		t.Errorf("unexpected header %s", lines[0])
+	}
+
+	var in, out string
+	for _, l := range lines[1:] {
+		var ev [3]any
+		if err := json.Unmarshal([]byte(l), &ev); err != nil {
+			t.Fatal(err)
+		}
+		switch ev[1] {
+		case "i":
+			in += ev[2].(string)
+		case "o":
+			out += ev[2].(string)
+		}
+	}
+	if in != "a\x0d" {
+		t.Errorf(`expected "a\x0d" got %#v`, in)
+	}
+	if out != ch.String() {
+		t.Errorf("expected %#v got %#v", ch.String(), out)
+	}
+}
+
+func TestTerminal_RecordTypescript(t *testing.T) {
+	var script, timing bytes.Buffer
+	rec, err := NewTypescript(&script, &timing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := &Terminal{
+		Inp:    bufio.NewReader(bytes.NewBufferString("a\x0d")),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+	}
+	e.out = &bytes.Buffer{}
+	if err := e.Record(rec, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.LineEditor(); err != nil {
+		t.Fatal(err)
+	}
+
+	header, data, _ := strings.Cut(script.String(), "\n")
+	if !strings.HasPrefix(header, "Script started on ") {
+		t.Errorf("unexpected header %#v", header)
+	}
+	if data != "\r> \x1b[0K\r\x1b[2C\r> a\x1b[0K\r\x1b[3C" {
+		t.Errorf("unexpected data %#v", data)
+	}
+
+	total := 0
+	for _, l := range strings.Split(strings.TrimSuffix(timing.String(), "\n"), "\n") {
+		m := regexp.MustCompile(`^\d+\.\d{6} (\d+)$`).FindStringSubmatch(l)
+		if m == nil {
+			t.Fatalf("unexpected timing %#v", l)
+		}
+		n, _ := strconv.Atoi(m[1])
+		total += n
+	}
+	if total != len(data) {
+		t.Errorf("expected %d bytes in timing got %d", len(data), total)
+	}
+}
+
+func TestTerminal_RecordAsciicastSplitRune(t *testing.T) {
+	var cast bytes.Buffer
+	rec, err := NewAsciicast(&cast, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := rec.Output()(&bytes.Buffer{})
+	for _, p := range []string{"a\xc3", "\xa9b\xe2\x82", "\xac"} {
+		if _, err := w.Write([]byte(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out []string
+	for _, l := range strings.Split(strings.TrimSuffix(cast.String(), "\n"), "\n")[1:] {
+		var ev [3]any
+		if err := json.Unmarshal([]byte(l), &ev); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, ev[2].(string))
+	}
+	if want := []string{"a", "éb", "€"}; !slices.Equal(out, want) {
+		t.Errorf("expected %q got %q", want, out)
+	}
+}
+
+func TestTerminal_RecordTypescriptInputDelay(t *testing.T) {
+	var script, timing bytes.Buffer
+	rec, err := NewTypescript(&script, &timing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := rec.Output()(&bytes.Buffer{})
+	in := rec.Input(strings.NewReader("k"))
+	w.Write([]byte("a"))
+	time.Sleep(20 * time.Millisecond)
+	in.Read(make([]byte, 1)) // dropped, doesn't count as output
+	w.Write([]byte("b"))
+
+	lines := strings.Split(strings.TrimSuffix(timing.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 timings got %q", lines)
+	}
+	var d float64
+	var n int
+	if _, err := fmt.Sscanf(lines[1], "%f %d", &d, &n); err != nil {
+		t.Fatal(err)
+	}
+	if d < 0.02 || n != 1 {
+		t.Errorf("expected at least 20ms before 1 byte got %q", lines[1])
+	}
+}
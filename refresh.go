@@ -0,0 +1,23 @@
+package linenoisy
+
+// Refresh clears the line being edited and draws it again in place.
+// Like WriteOut, it is safe to call from other goroutines.
+func (e *Terminal) Refresh() error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	return e.repaint()
+}
+
+// RefreshAll forgets what was drawn before and draws the status, the status bar and the line
+// anew from the row of the cursor, e.g. after the application printed around the editor or the screen was cleared.
+func (e *Terminal) RefreshAll() error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	if !e.active || e.suspended {
+		return nil
+	}
+	e.leaveRegion()
+	return e.resume()
+}
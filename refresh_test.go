@@ -0,0 +1,36 @@
+package linenoisy
+
+import (
+	"bufio"
+	"testing"
+)
+
+func TestEditor_Refresh(t *testing.T) {
+	out := &checkedWriter{
+		expectations: []string{
+			"\x1b[1A\r\x1b[0J\r> foo bar\x1b[0K\r\x1b[5C",
+			"\r> foo bar\x1b[0K\r\x1b[5C",
+		},
+	}
+
+	e := &Terminal{
+		Out:     bufio.NewWriter(out),
+		Prompt:  "> ",
+		Buffer:  []rune("foo bar"),
+		Cur:     3,
+		MaxRows: 1,
+		curRow:  1,
+		active:  true,
+	}
+
+	if err := e.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	e.curRow = 1 // the application moved the cursor, the region is gone
+	if err := e.RefreshAll(); err != nil {
+		t.Fatal(err)
+	}
+	if e.curRow != 0 {
+		t.Errorf("expected 0 got %d", e.curRow)
+	}
+}
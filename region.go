@@ -0,0 +1,81 @@
+package linenoisy
+
+import "slices"
+
+// region returns the selected runes of Buffer, between the mark and the cursor.
+func (e *Terminal) region() (from, to int, ok bool) {
+	if !e.marked {
+		return 0, 0, false
+	}
+	m := min(max(e.mark, 0), len(e.Buffer))
+	return min(m, e.Cur), max(m, e.Cur), true
+}
+
+// selectKey updates the region before k is handled. Shift with a motion starts or extends the region
+// and is taken off k, motions extend a region started by Ctrl-Space, region operations keep it,
+// every other key deselects it.
+func (e *Terminal) selectKey(k Key) (Key, error) {
+	unshifted := k
+	unshifted.Mod &^= ModShift
+
+	switch {
+	case isMotion(unshifted) && k.Mod&ModShift != 0:
+		if !e.marked || !e.shiftMark {
+			e.mark, e.marked, e.shiftMark = e.Cur, true, true
+		}
+		return unshifted, nil
+	case !e.marked:
+	case isMotion(k) && !e.shiftMark:
+	case k == ctrl('w'), k == Key{Rune: 'w', Mod: ModAlt}, k == Key{Code: KeyBackspace}, k == Key{Code: KeyDelete}:
+	default:
+		e.marked = false
+		return k, e.refreshLine()
+	}
+	return k, nil
+}
+
+func isMotion(k Key) bool {
+	switch k {
+	case Key{Code: KeyRight}, Key{Code: KeyLeft}, Key{Code: KeyHome}, Key{Code: KeyEnd},
+		ctrl('f'), ctrl('b'), ctrl('a'), ctrl('e'),
+		Key{Code: KeyRight, Mod: ModCtrl}, Key{Code: KeyRight, Mod: ModAlt}, Key{Rune: 'f', Mod: ModAlt},
+		Key{Code: KeyLeft, Mod: ModCtrl}, Key{Code: KeyLeft, Mod: ModAlt}, Key{Rune: 'b', Mod: ModAlt},
+		ctrl(']'), Key{Rune: ']', Mod: ModCtrl | ModAlt}:
+		return true
+	}
+	return false
+}
+
+// editSetMark starts a region at the cursor which the following motions extend.
+func (e *Terminal) editSetMark() error {
+	e.mark, e.marked, e.shiftMark = e.Cur, true, false
+	return e.refreshLine()
+}
+
+// editKillRegion kills the selected text.
+func (e *Terminal) editKillRegion() error {
+	from, to, ok := e.region()
+	if !ok {
+		return e.beep()
+	}
+	e.kill(e.Buffer[from:to])
+	return e.deleteRegion(from, to)
+}
+
+// editCopyRegion puts the selected text on the kill ring without deleting it.
+func (e *Terminal) editCopyRegion() error {
+	from, to, ok := e.region()
+	if !ok {
+		return e.beep()
+	}
+	e.kill(e.Buffer[from:to])
+	e.marked = false
+	return e.refreshLine()
+}
+
+func (e *Terminal) deleteRegion(from, to int) error {
+	e.Buffer = slices.Delete(e.Buffer, from, to)
+	e.Cur = from
+	e.marked = false
+	return e.refreshLine()
+}
@@ -0,0 +1,55 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEditor_Region(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   string
+		line string
+	}{
+		{"shift select and kill", "hello world" + strings.Repeat("\x1b[1;2D", 5) + "\x17\x01\x19\x0d", "worldhello "},
+		{"set mark and copy", "foo bar\x01\x00\x1bf\x1bw\x05\x19\x0d", "foo barfoo"},
+		{"backspace deletes the region", "abcdef\x1b[1;2H\x7fx\x0d", "x"},
+		{"delete deletes the region", "abcdef\x1b[1;2D\x1b[1;2D\x1b[3~\x0d", "abcd"},
+		{"plain motion deselects", "abc\x1b[1;2D\x1b[D\x7f\x0d", "bc"},
+		{"typing deselects", "abc\x1b[1;2Dx\x7f\x0d", "abc"},
+		{"ctrl-w without region", "foo bar\x17\x0d", "foo "},
+	} {
+		e := &Terminal{
+			Inp:    bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:    bufio.NewWriter(&bytes.Buffer{}),
+			Prompt: "> ",
+		}
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if l != tt.line {
+			t.Errorf("%s: expected %q got %q", tt.name, tt.line, l)
+		}
+	}
+}
+
+func TestEditor_RegionReverseVideo(t *testing.T) {
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp:    bufio.NewReader(bytes.NewBufferString("hello world" + strings.Repeat("\x1b[1;2D", 5) + "\x0d")),
+		Out:    bufio.NewWriter(&out),
+		Prompt: "> ",
+	}
+	if _, err := e.LineEditor(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "\r> hello \x1b[7mworld\x1b[27m\x1b[0K") {
+		t.Errorf("expected the region in reverse video got %q", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "\r> hello world\x1b[0K\r\x1b[8C") {
+		t.Errorf("expected Enter to deselect got %q", out.String())
+	}
+}
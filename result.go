@@ -0,0 +1,48 @@
+package linenoisy
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// LineEnd tells how LineEditorEx ended a line.
+type LineEnd int
+
+const (
+	EndEnter     LineEnd = iota // the line was confirmed.
+	EndEOF                      // Ctrl-D on an empty line or the end of the input.
+	EndInterrupt                // Ctrl-C.
+	EndTimeout                  // a read deadline of the transport expired.
+	EndError                    // any other error.
+)
+
+// LineResult describes a line read by LineEditorEx.
+type LineResult struct {
+	Line        string
+	End         LineEnd
+	Duration    time.Duration // from the start of LineEditorEx to the end of the line.
+	HistoryUsed bool          // the line was recalled from or expanded with history.
+}
+
+// LineEditorEx is LineEditor returning how the line ended and how it was edited along with it.
+func (e *Terminal) LineEditorEx() (LineResult, error) {
+	start := time.Now()
+	l, err := e.LineEditor()
+	r := LineResult{Line: l, Duration: time.Since(start), HistoryUsed: e.historyUsed}
+
+	var timeout interface{ Timeout() bool }
+	switch {
+	case err == nil:
+		r.End = EndEnter
+	case errors.Is(err, ErrInterrupted):
+		r.End = EndInterrupt
+	case errors.Is(err, io.EOF):
+		r.End = EndEOF
+	case errors.As(err, &timeout) && timeout.Timeout():
+		r.End = EndTimeout
+	default:
+		r.End = EndError
+	}
+	return r, err
+}
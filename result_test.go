@@ -0,0 +1,51 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+type timeoutReader struct{}
+
+func (timeoutReader) Read([]byte) (int, error) { return 0, os.ErrDeadlineExceeded }
+
+func TestEditor_LineEditorEx(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		in      *bufio.Reader
+		line    string
+		end     LineEnd
+		history bool
+	}{
+		{"enter", bufio.NewReader(bytes.NewBufferString("foo\x0d")), "foo", EndEnter, false},
+		{"history", bufio.NewReader(bytes.NewBufferString("\x1b[A\x0d")), "bar", EndEnter, true},
+		{"interrupt", bufio.NewReader(bytes.NewBufferString("foo\x03")), "foo", EndInterrupt, false},
+		{"ctrl-d", bufio.NewReader(bytes.NewBufferString("\x04")), "", EndEOF, false},
+		{"eof", bufio.NewReader(bytes.NewBufferString("fo")), "fo", EndEOF, false},
+		{"timeout", bufio.NewReader(timeoutReader{}), "", EndTimeout, false},
+	} {
+		e := &Terminal{
+			Inp:    tt.in,
+			Out:    bufio.NewWriter(&bytes.Buffer{}),
+			Prompt: "> ",
+		}
+		e.History.Add("bar")
+
+		r, err := e.LineEditorEx()
+		if (err == nil) != (tt.end == EndEnter) {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if tt.end == EndTimeout && !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("%s: expected the deadline error got %v", tt.name, err)
+		}
+		if r.Line != tt.line || r.End != tt.end || r.HistoryUsed != tt.history {
+			t.Errorf("%s: expected %q %d %v got %q %d %v", tt.name, tt.line, tt.end, tt.history, r.Line, r.End, r.HistoryUsed)
+		}
+		if r.Duration <= 0 {
+			t.Errorf("%s: expected a duration", tt.name)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package linenoisy
+
+import "io"
+
+// SilentLineEditor reads a line like LineEditor but echoes nothing of it, not even its length,
+// e.g. for passwords or challenge responses. Only the prompt is drawn. Editing keys work as usual,
+// while history, completion, help, hints, the kill ring and the bell are off.
+func (e *Terminal) SilentLineEditor() (string, error) {
+	e.edit.Lock()
+	e.silent = true
+	e.edit.Unlock()
+
+	defer func() {
+		e.edit.Lock()
+		e.silent = false
+		e.edit.Unlock()
+	}()
+	return e.LineEditor()
+}
+
+// refreshSilent draws the prompt with the cursor after it, the same whatever the input is.
+func (e *Terminal) refreshSilent() error {
+	e.notZero()
+	e.drawn = nil
+	ew := &errWriter{w: e.Out}
+	if e.curRow > 0 {
+		ew.writeString(e.caps().up(e.curRow))
+	}
+	ew.writeString("\r")
+	head := e.writePrompt(ew)
+	ew.writeString(e.caps().ClearEOL)
+	ew.flush()
+
+	e.MaxRows = head
+	e.curRow = head
+	e.OldCur = e.Cur
+	return ew.err
+}
+
+// handleSilentKey applies the editing keys of SilentLineEditor to the line.
+func (e *Terminal) handleSilentKey(k Key) (string, bool, error) {
+	var err error
+	switch k {
+	case Key{Code: KeyEnter}:
+		return string(e.Buffer), true, nil
+	case ctrl('c'):
+		return string(e.Buffer), true, ErrInterrupted
+	case ctrl('d'):
+		if len(e.Buffer) == 0 {
+			return string(e.Buffer), true, io.EOF
+		}
+		err = e.editDelete()
+	case Key{Code: KeyBackspace}, ctrl('h'):
+		err = e.editBackspace()
+	case Key{Code: KeyDelete}:
+		err = e.editDelete()
+	case Key{Code: KeyRight}, ctrl('f'):
+		err = e.editMoveRight()
+	case Key{Code: KeyLeft}, ctrl('b'):
+		err = e.editMoveLeft()
+	case Key{Code: KeyHome}, ctrl('a'):
+		err = e.editMoveHome()
+	case Key{Code: KeyEnd}, ctrl('e'):
+		err = e.editMoveEnd()
+	case ctrl('w'):
+		err = e.editDeletePrevWord()
+	case ctrl('u'):
+		err = e.editKillBackward()
+	case ctrl('k'):
+		err = e.editKillForward()
+	case ctrl('l'):
+		if err := e.clearScreen(); err != nil {
+			return string(e.Buffer), true, err
+		}
+		err = e.refreshLine()
+	default:
+		if k.Code == KeyRune && k.Mod == 0 {
+			err = e.editInsertRunes([]rune{k.Rune})
+		}
+	}
+	return string(e.Buffer), err != nil, err
+}
@@ -0,0 +1,41 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEditor_SilentLineEditor(t *testing.T) {
+	in := bytes.NewBufferString("hunter\x7f\x7f\x7f\x02\x02\x7fxyz\x1b[A\t\x0d")
+	var out bytes.Buffer
+	e := &Terminal{
+		Inp:    bufio.NewReader(in),
+		Out:    bufio.NewWriter(&out),
+		Prompt: "password: ",
+		Hint:   func(string) string { return "hint" },
+		Complete: func(string) []string {
+			t.Error("unexpected completion")
+			return nil
+		},
+	}
+	e.History.Add("previous")
+
+	l, err := e.SilentLineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "xyzun" {
+		t.Errorf(`expected "xyzun" got %#v`, l)
+	}
+	if s := strings.ReplaceAll(out.String(), "\r"+e.Prompt+"\x1b[0K", ""); s != "" {
+		t.Errorf("expected only prompts got %q", s)
+	}
+	if e.silent {
+		t.Error("expected silent mode to end")
+	}
+	if len(e.killRing) != 0 {
+		t.Errorf("expected empty kill ring got %q", e.killRing)
+	}
+}
@@ -0,0 +1,85 @@
+package linenoisy
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errShortPayload = errors.New("ssh request payload is too short")
+
+// PtyRequest is the payload of the "pty-req" SSH channel request (RFC 4254 6.2).
+type PtyRequest struct {
+	Term string
+	Cols int
+	Rows int
+}
+
+// ParsePtyRequest decodes the payload of a "pty-req" request.
+func ParsePtyRequest(payload []byte) (PtyRequest, error) {
+	if len(payload) < 4 {
+		return PtyRequest{}, errShortPayload
+	}
+	n := int(binary.BigEndian.Uint32(payload))
+	payload = payload[4:]
+	if len(payload) < n+8 {
+		return PtyRequest{}, errShortPayload
+	}
+
+	cols, rows := dims(payload[n:])
+	return PtyRequest{
+		Term: string(payload[:n]),
+		Cols: cols,
+		Rows: rows,
+	}, nil
+}
+
+// ParseWindowChange decodes the payload of a "window-change" request (RFC 4254 6.7).
+func ParseWindowChange(payload []byte) (cols, rows int, err error) {
+	if len(payload) < 8 {
+		return 0, 0, errShortPayload
+	}
+	cols, rows = dims(payload)
+	return cols, rows, nil
+}
+
+// HandleSSHRequest applies "pty-req" and "window-change" requests of an SSH session channel to the Terminal.
+// It reports whether the request was one of them, so the caller can reply and deal with the rest:
+//
+//	for req := range reqs {
+//		ok, err := e.HandleSSHRequest(req.Type, req.Payload)
+//		...
+//	}
+func (e *Terminal) HandleSSHRequest(typ string, payload []byte) (bool, error) {
+	switch typ {
+	case "pty-req":
+		pty, err := ParsePtyRequest(payload)
+		if err != nil {
+			return true, err
+		}
+		e.edit.Lock()
+		e.Term = pty.Term
+		e.edit.Unlock()
+		return true, e.resize(pty.Cols, pty.Rows)
+	case "window-change":
+		cols, rows, err := ParseWindowChange(payload)
+		if err != nil {
+			return true, err
+		}
+		return true, e.resize(cols, rows)
+	}
+	return false, nil
+}
+
+// resize ignores zero dimensions, which clients send when they only report the size in pixels.
+func (e *Terminal) resize(cols, rows int) error {
+	if cols <= 0 || rows <= 0 {
+		return nil
+	}
+	return e.SetSize(cols, rows)
+}
+
+func dims(b []byte) (cols, rows int) {
+	cols = int(binary.BigEndian.Uint32(b))
+	rows = int(binary.BigEndian.Uint32(b[4:]))
+	return
+}
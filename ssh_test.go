@@ -0,0 +1,52 @@
+package linenoisy
+
+import "testing"
+
+func TestEditor_HandleSSHRequest(t *testing.T) {
+	e := &Terminal{}
+
+	pty := []byte("\x00\x00\x00\x05xterm\x00\x00\x00\x64\x00\x00\x00\x1e\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	ok, err := e.HandleSSHRequest("pty-req", pty)
+	if err != nil {
+		t.Error(err)
+	}
+	if !ok {
+		t.Error("expected pty-req to be handled")
+	}
+	if e.Cols != 100 || e.Rows != 30 {
+		t.Errorf("expected 100x30 got %dx%d", e.Cols, e.Rows)
+	}
+
+	ok, err = e.HandleSSHRequest("window-change", []byte("\x00\x00\x00\x78\x00\x00\x00\x28\x00\x00\x00\x00\x00\x00\x00\x00"))
+	if err != nil {
+		t.Error(err)
+	}
+	if !ok {
+		t.Error("expected window-change to be handled")
+	}
+	if e.Cols != 120 || e.Rows != 40 {
+		t.Errorf("expected 120x40 got %dx%d", e.Cols, e.Rows)
+	}
+
+	if ok, _ := e.HandleSSHRequest("shell", nil); ok {
+		t.Error("expected shell not to be handled")
+	}
+
+	if _, err := e.HandleSSHRequest("window-change", []byte{0, 0}); err == nil {
+		t.Error("err expected")
+	}
+}
+
+func TestParsePtyRequest(t *testing.T) {
+	pty, err := ParsePtyRequest([]byte("\x00\x00\x00\x05xterm\x00\x00\x00\x50\x00\x00\x00\x18"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pty != (PtyRequest{Term: "xterm", Cols: 80, Rows: 24}) {
+		t.Errorf("unexpected %#v", pty)
+	}
+
+	if _, err := ParsePtyRequest([]byte("\x00\x00\x00\x05xt")); err == nil {
+		t.Error("err expected")
+	}
+}
@@ -0,0 +1,127 @@
+package linenoisy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const spinnerTick = 100 * time.Millisecond
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// SetStatus shows s on its own row just above the prompt while LineEditor is running, an empty s removes the row.
+// Like WriteOut, it is safe to call from other goroutines, but not from the callbacks of LineEditor.
+func (e *Terminal) SetStatus(s string) error {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	e.status = s
+	if !e.active || e.suspended {
+		return nil // drawn by the next LineEditor or Resume
+	}
+	return e.redrawStatus()
+}
+
+// Spinner shows a spinner followed by msg in the status row, turning every 100ms until stop is called,
+// which removes the row again.
+func (e *Terminal) Spinner(msg string) (stop func()) {
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tk := time.NewTicker(spinnerTick)
+		defer tk.Stop()
+
+		for i := 0; ; i++ {
+			e.SetStatus(spinnerFrames[i%len(spinnerFrames)] + " " + msg)
+			select {
+			case <-quit:
+				return
+			case <-tk.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(quit)
+			<-done
+			e.SetStatus("")
+		})
+	}
+}
+
+// Progress formats a progress bar of width columns for done of total, to be shown by SetStatus:
+//
+//	[=========>          ]  47%
+func Progress(done, total, width int) string {
+	if total <= 0 {
+		total = 1
+	}
+	done = min(max(done, 0), total)
+	bar := max(width-7, 1) // brackets and percentage
+	n := bar * done / total
+
+	var sb strings.Builder
+	sb.WriteString("[")
+	sb.WriteString(strings.Repeat("=", n))
+	if n < bar {
+		sb.WriteString(">")
+		sb.WriteString(strings.Repeat(" ", bar-n-1))
+	}
+	sb.WriteString(fmt.Sprintf("] %3d%%", 100*done/total))
+	return sb.String()
+}
+
+// hideStatus removes the status row for good, the edit region moves up into it.
+func (e *Terminal) hideStatus() error {
+	if !e.statusShown || e.suspended {
+		return nil
+	}
+	status := e.status
+	e.status = ""
+	err := e.redrawStatus()
+	e.status = status
+	return err
+}
+
+// redrawStatus clears the edit region and the status row above it, then draws both again.
+func (e *Terminal) redrawStatus() error {
+	e.notZero()
+	ew := &errWriter{w: e.Out}
+	e.clearRegion(ew)
+	e.clearStatus(ew)
+	e.writeStatus(ew)
+	ew.flush()
+	if ew.err != nil {
+		return ew.err
+	}
+
+	e.OldCur = 0
+	e.MaxRows = 0
+	e.curRow = 0
+	return e.refreshLine()
+}
+
+// clearStatus erases the status row, the cursor has to be at the beginning of the edit region and ends on that row.
+func (e *Terminal) clearStatus(ew *errWriter) {
+	if !e.statusShown {
+		return
+	}
+	ew.writeString(e.caps().up(1) + "\r" + e.caps().ClearEOL)
+	e.statusShown = false
+}
+
+// writeStatus writes the status row, the edit region starts on the row after it.
+func (e *Terminal) writeStatus(ew *errWriter) {
+	if e.status == "" || !e.active {
+		return
+	}
+	ew.writeString("\r")
+	ew.writeString(e.truncate(e.status, e.Cols-1))
+	ew.writeString("\r\n")
+	e.statusShown = true
+}
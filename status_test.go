@@ -0,0 +1,83 @@
+package linenoisy
+
+import (
+	"bufio"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Joker/linenoisy/vtest"
+)
+
+func TestEditor_SetStatus(t *testing.T) {
+	s := vtest.New(20, 5)
+	e := &Terminal{
+		Out:    bufio.NewWriter(s),
+		Prompt: "> ",
+		Cols:   20,
+		Rows:   5,
+		Buffer: []rune("abc"),
+		Cur:    1,
+		active: true,
+	}
+	if err := e.refreshLine(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		do   func() error
+		want []string
+	}{
+		{func() error { return e.SetStatus("working") }, []string{"working", "> abc", "", "", ""}},
+		{func() error { return e.SetStatus(Progress(1, 2, 14)) }, []string{"[===>   ]  50%", "> abc", "", "", ""}},
+		{func() error { _, err := e.WriteOut([]byte("log")); return err }, []string{"log", "[===>   ]  50%", "> abc", "", ""}},
+		{func() error { return e.SetStatus("") }, []string{"log", "> abc", "", "", ""}},
+	} {
+		if err := step.do(); err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Lines(); !slices.Equal(got, step.want) {
+			t.Errorf("expected %q got %q", step.want, got)
+		}
+		if c, r := s.Cursor(); c != 3 || r != slices.Index(step.want, "> abc") {
+			t.Errorf("expected the cursor after > a got %d,%d", c, r)
+		}
+	}
+}
+
+func TestEditor_Spinner(t *testing.T) {
+	s := vtest.New(20, 5)
+	out := bufio.NewWriter(s)
+	e := &Terminal{Out: out, Prompt: "> ", Cols: 20, Rows: 5, active: true}
+	if err := e.refreshLine(); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := e.Spinner("loading")
+	waitFor(t, "the spinner", func() bool {
+		e.edit.Lock()
+		defer e.edit.Unlock()
+		return strings.HasSuffix(e.status, " loading")
+	})
+	stop()
+	stop()
+
+	if got := s.Lines(); got[0] != ">" {
+		t.Errorf("expected the status removed got %q", got)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	for _, tt := range []struct {
+		done, total, width int
+		want               string
+	}{
+		{0, 10, 17, "[>         ]   0%"},
+		{10, 10, 17, "[==========] 100%"},
+		{5, 0, 10, "[===] 100%"},
+	} {
+		if got := Progress(tt.done, tt.total, tt.width); got != tt.want {
+			t.Errorf("expected %q got %q", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package linenoisy
+
+import "fmt"
+
+// refreshStatusBar draws StatusBar on the last row of the terminal, keeping the other rows scrolling
+// above it by a scroll region. Without StatusBar a pinned bar is removed again.
+func (e *Terminal) refreshStatusBar() error {
+	if e.StatusBar == nil && e.barRows == 0 {
+		return nil
+	}
+	e.notZero()
+	ew := &errWriter{w: e.Out}
+	switch {
+	case e.StatusBar == nil:
+		e.removeStatusBar(ew)
+	case e.barRows != e.Rows:
+		// make room for the bar, then keep the output above it; setting the region homes the cursor
+		ew.writeString("\n" + e.caps().up(1))
+		ew.writeString(fmt.Sprintf("\x1b7\x1b[1;%dr\x1b8", e.Rows-1))
+		e.barRows = e.Rows
+		fallthrough
+	default:
+		ew.writeString(fmt.Sprintf("\x1b7\x1b[%d;1H", e.Rows))
+		ew.writeString(e.caps().ClearLine)
+		ew.writeString("\r")
+		ew.writeString(e.truncate(e.StatusBar(), e.Cols-1))
+		ew.writeString("\x1b8")
+	}
+	ew.flush()
+	return ew.err
+}
+
+// removeStatusBar gives the last row back to the output.
+func (e *Terminal) removeStatusBar(ew *errWriter) {
+	if e.barRows == 0 {
+		return
+	}
+	ew.writeString(fmt.Sprintf("\x1b7\x1b[r\x1b[%d;1H", e.barRows))
+	ew.writeString(e.caps().ClearLine)
+	ew.writeString("\x1b8")
+	e.barRows = 0
+}
@@ -0,0 +1,46 @@
+package linenoisy
+
+import (
+	"bufio"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Joker/linenoisy/vtest"
+)
+
+func TestEditor_StatusBar(t *testing.T) {
+	s := vtest.New(20, 3)
+	keys := 0
+	e := &Terminal{
+		Out:       bufio.NewWriter(s),
+		Prompt:    "> ",
+		Cols:      20,
+		Rows:      3,
+		StatusBar: func() string { return strings.Repeat("*", keys) },
+	}
+
+	e.Inp = bufio.NewReader(strings.NewReader("ab\r"))
+	if _, err := e.LineEditor(); err != nil {
+		t.Fatal(err)
+	}
+	keys = 2
+
+	e.Out.WriteString("\r\nout\r\n")
+	e.Inp = bufio.NewReader(strings.NewReader("c\r"))
+	if _, err := e.LineEditor(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"out", "> c", "**"}; !slices.Equal(s.Lines(), want) {
+		t.Errorf("expected %q got %q", want, s.Lines())
+	}
+
+	e.StatusBar = nil
+	e.Inp = bufio.NewReader(strings.NewReader("\r"))
+	if _, err := e.LineEditor(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"out", ">", ""}; !slices.Equal(s.Lines(), want) {
+		t.Errorf("expected the bar removed got %q", s.Lines())
+	}
+}
@@ -0,0 +1,187 @@
+package linenoisy
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// KeyEvent is a key decoded by KeyEvents, or a command sent on Commands.
+type KeyEvent struct {
+	Key     Key
+	Text    string // the content of a KeyPaste or KeyClipboard.
+	Command string // the editing function of Bind a command names, Key is then empty.
+	Err     error  // the error which ended the input, Key is then empty.
+}
+
+// KeyEvents decodes the keys of Inp in a goroutine and sends them on the returned channel, so a server
+// can select on them together with its timers and other channels and pass each to HandleKey.
+// Commands sent on Commands come on the same channel in between the keys.
+// The channel is closed after an event with Err or once ctx is done; the decoding ends with the next key.
+//
+// From then on, keys read by the editor itself, like the keys of Ctrl-R, also come from the channel;
+// LineEditor must not be used anymore.
+func (e *Terminal) KeyEvents(ctx context.Context) <-chan KeyEvent {
+	ch := make(chan KeyEvent)
+	keys := make(chan KeyEvent)
+	d := &Terminal{Inp: e.Inp, EscTimeout: e.EscTimeout, SeqTimeout: e.SeqTimeout}
+
+	e.edit.Lock()
+	e.stream = ch
+	cmds := e.commandChan()
+	e.edit.Unlock()
+
+	go func() {
+		for {
+			k, err := d.readKey()
+			ev := KeyEvent{Key: k, Err: err}
+			switch k.Code {
+			case KeyPaste:
+				ev.Text = d.pasted
+			case KeyClipboard:
+				ev.Text = d.clipboard
+			}
+
+			select {
+			case keys <- ev:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer close(ch)
+		for {
+			var ev KeyEvent
+			select {
+			case ev = <-keys:
+			case name, ok := <-cmds:
+				if !ok {
+					cmds = nil // no more commands, keep on with the keys
+					continue
+				}
+				ev = KeyEvent{Command: name}
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return
+			}
+			if ev.Err != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// Commands returns the channel on which the application injects actions, like a timer or a message from
+// another session resetting the input with "kill-whole-line". A command names an editing function of Bind
+// and is applied by HandleKey once it comes on the channel of KeyEvents, in order with the keys. Commands
+// must be sent from other goroutines than the one passing the events to HandleKey.
+func (e *Terminal) Commands() chan<- string {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	return e.commandChan()
+}
+
+func (e *Terminal) commandChan() chan string {
+	if e.commands == nil {
+		e.commands = make(chan string)
+	}
+	return e.commands
+}
+
+// HandleKey applies a key of KeyEvents like LineEditor does, starting a new line first if none is being edited.
+// Once done is true, line is the one LineEditor would return with err, and the next key starts a new line.
+func (e *Terminal) HandleKey(ev KeyEvent) (line string, done bool, err error) {
+	e.edit.Lock()
+	active := e.active
+	e.edit.Unlock()
+
+	if ev.Err != nil {
+		if active {
+			e.end()
+		}
+		return string(e.Buffer), true, ev.Err
+	}
+	if ev.Command != "" {
+		return string(e.Buffer), false, e.Do(ev.Command)
+	}
+	if !active {
+		if err := e.begin(); err != nil {
+			e.end()
+			return string(e.Buffer), true, err
+		}
+	}
+
+	e.edit.Lock()
+	e.takeText(ev)
+	e.edit.Unlock()
+	line, done, err = e.step(ev.Key)
+	for err == nil && !done && len(e.queued) > 0 {
+		name := e.queued[0]
+		e.queued = e.queued[1:]
+		err = e.Do(name)
+		line = e.Line()
+	}
+	if done || err != nil {
+		e.queued = nil // meant for the line which is over
+		e.end()
+		return line, true, err
+	}
+	return line, false, nil
+}
+
+// Do runs the editing function of Bind with the given name on the line being edited,
+// e.g. Do("kill-whole-line") when the application resets the input. Unlike a command sent on Commands,
+// it runs right away and is meant for the goroutine passing the events to HandleKey.
+func (e *Terminal) Do(name string) error {
+	f, ok := editFuncs[name]
+	if !ok {
+		return fmt.Errorf("unknown editing function %q", name)
+	}
+
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	if !e.active {
+		return nil
+	}
+	return f(e)
+}
+
+// streamKey receives the next key for the editor itself from the channel of KeyEvents.
+// Commands coming in the meantime wait for HandleKey to apply them once the key it handles is done.
+func (e *Terminal) streamKey() (Key, error) {
+	for {
+		ev, ok := <-e.stream
+		if !ok {
+			return Key{}, io.EOF
+		}
+		if ev.Command != "" {
+			e.queued = append(e.queued, ev.Command)
+			continue
+		}
+		e.takeText(ev)
+		return ev.Key, ev.Err
+	}
+}
+
+// takeText keeps the text of a paste or clipboard key for its handling.
+func (e *Terminal) takeText(ev KeyEvent) {
+	switch ev.Key.Code {
+	case KeyPaste:
+		e.pasted = ev.Text
+	case KeyClipboard:
+		e.clipboard = ev.Text
+	}
+}
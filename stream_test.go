@@ -0,0 +1,103 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEditor_KeyEvents(t *testing.T) {
+	pr, pw := io.Pipe()
+	ch := &rwc{Reader: pr}
+	e := NewTerminal(ch, "> ")
+	go pw.Write([]byte("echo hi\rfoo\x12ech\x1b[C\rbar"))
+
+	keys := e.KeyEvents(context.Background())
+	cmds := e.Commands()
+	var lines []string
+	for ev := range keys {
+		l, done, err := e.HandleKey(ev)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done {
+			lines = append(lines, l)
+			e.History.Add(l)
+		}
+		if l == "bar" && ev.Command == "" {
+			go func() {
+				cmds <- "unix-line-discard" // e.g. from a timer
+				pw.Close()
+			}()
+		}
+	}
+
+	if len(lines) != 2 || lines[0] != "echo hi" || lines[1] != "echo hi" {
+		t.Errorf(`expected "echo hi" twice got %q`, lines)
+	}
+	if e.Line() != "" {
+		t.Errorf(`expected an empty line got %#v`, e.Line())
+	}
+}
+
+func TestEditor_KeyEventsCommandInSearch(t *testing.T) {
+	pr, pw := io.Pipe()
+	out := &syncBuffer{}
+	e := &Terminal{
+		Inp:    bufio.NewReader(pr),
+		Out:    bufio.NewWriter(out),
+		Prompt: "> ",
+	}
+	e.History.Add("echo hi")
+
+	keys := e.KeyEvents(context.Background())
+	cmds := e.Commands()
+	go func() {
+		pw.Write([]byte("x\x12ech"))
+		for !strings.Contains(out.String(), "`ech'") {
+			time.Sleep(time.Millisecond)
+		}
+		cmds <- "end-of-line" // comes while Ctrl-R reads its keys
+		pw.Write([]byte("\x1b[C!\r"))
+	}()
+
+	for ev := range keys {
+		l, done, err := e.HandleKey(ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done {
+			if l != "echo hi!" {
+				t.Errorf(`expected "echo hi!" got %q`, l)
+			}
+			break
+		}
+	}
+}
+
+func TestEditor_Do(t *testing.T) {
+	e := NewTerminal(&rwc{Reader: bytes.NewBufferString("foo")}, "> ")
+	keys := e.KeyEvents(context.Background())
+	for range 3 {
+		if _, _, err := e.HandleKey(<-keys); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := e.Do("unix-line-discard"); err != nil {
+		t.Fatal(err)
+	}
+	if e.Line() != "" {
+		t.Errorf(`expected an empty line got %#v`, e.Line())
+	}
+	if err := e.Do("no-such-function"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,56 @@
+package linenoisy
+
+import (
+	"bufio"
+	"io"
+	"slices"
+	"strings"
+)
+
+// stuffReader reads the bytes put back by Stuff before the input beneath it.
+type stuffReader struct {
+	pending   []byte
+	r         io.Reader
+	inp       *bufio.Reader // the Inp reading from it.
+	delivered int           // bytes read since the last Stuff.
+	keysEnd   int           // where the stuffed keys end among them.
+}
+
+func (s *stuffReader) Read(p []byte) (n int, err error) {
+	if len(s.pending) == 0 {
+		n, err = s.r.Read(p)
+	} else {
+		n = copy(p, s.pending)
+		s.pending = s.pending[n:]
+	}
+	s.delivered += n
+	return n, err
+}
+
+// Stuff queues keys as if they were typed, escape sequences included, e.g. to script a demo or to accept
+// a suggestion the application offers. They are handled after the keys stuffed before and ahead of
+// any other input not handled yet. It has to be called before LineEditor or from its goroutine,
+// like in OnKey or a binding.
+func (e *Terminal) Stuff(keys string) {
+	s := e.stuff
+	if s == nil || s.inp != e.Inp {
+		var r io.Reader = strings.NewReader("")
+		if e.Inp != nil {
+			r = e.Inp
+		}
+		s = &stuffReader{r: r}
+		s.inp = bufio.NewReader(s)
+		e.stuff = s
+		e.Inp = s.inp
+	}
+
+	// Take back what Inp buffered, the stuffed keys not handled yet stay in front.
+	b, _ := s.inp.Peek(s.inp.Buffered())
+	left := append(slices.Clone(b), s.pending...)
+	s.inp.Discard(len(b))
+	n := min(max(s.keysEnd-(s.delivered-len(b)), 0), len(left))
+
+	s.pending = slices.Concat(left[:n], []byte(keys), left[n:])
+	s.delivered = 0
+	s.keysEnd = n + len(keys)
+}
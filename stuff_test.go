@@ -0,0 +1,32 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestEditor_Stuff(t *testing.T) {
+	e := &Terminal{
+		Inp:    bufio.NewReader(bytes.NewBufferString("X!\r")),
+		Out:    bufio.NewWriter(&bytes.Buffer{}),
+		Prompt: "> ",
+	}
+	e.OnKey = func(k Key) (bool, error) {
+		if k == (Key{Rune: '!'}) {
+			e.Stuff("\x1b[D\x1b[D?")
+			e.Stuff("ab")
+			return true, nil
+		}
+		return false, nil
+	}
+	e.Stuff("hello\x01")
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "?abXhello" {
+		t.Errorf(`expected "?abXhello" got %#v`, l)
+	}
+}
@@ -0,0 +1,27 @@
+package linenoisy
+
+// submit finishes the line accepted by Enter: PreSubmit may rewrite it, then it is drawn for the last time,
+// behind the TransientPrompt and without the status row, and handed to PostSubmit.
+func (e *Terminal) submit(l string) (string, error) {
+	if e.PreSubmit != nil {
+		if s := e.PreSubmit(l); s != l {
+			l = s
+			e.Buffer = []rune(l)
+			e.Cur = len(e.Buffer)
+			if err := e.refreshLine(); err != nil {
+				return l, err
+			}
+		}
+	}
+
+	var err error
+	if e.TransientPrompt != nil && !e.silent {
+		err = e.redrawTransient(l)
+	} else {
+		err = e.hideStatus()
+	}
+	if err == nil && e.PostSubmit != nil {
+		e.PostSubmit(l)
+	}
+	return l, err
+}
@@ -0,0 +1,34 @@
+package linenoisy
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/Joker/linenoisy/vtest"
+)
+
+func TestEditor_Submit(t *testing.T) {
+	s := vtest.New(20, 3)
+	var shown, posted string
+	e := &Terminal{
+		Inp:       bufio.NewReader(strings.NewReader("  ll  \r")),
+		Out:       bufio.NewWriter(s),
+		Prompt:    "> ",
+		PreSubmit: func(line string) string { return strings.Replace(strings.TrimSpace(line), "ll", "ls -l", 1) },
+		PostSubmit: func(line string) {
+			shown, posted = s.Line(0), line
+		},
+	}
+
+	l, err := e.LineEditor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l != "ls -l" || posted != "ls -l" {
+		t.Errorf(`expected "ls -l" got %q and %q`, l, posted)
+	}
+	if shown != "> ls -l" {
+		t.Errorf(`expected "> ls -l" on the screen got %q`, shown)
+	}
+}
@@ -0,0 +1,49 @@
+package linenoisy
+
+import "fmt"
+
+// suggest accepts the line after offering the corrections of Suggest: a digit accepts one of them,
+// Enter the line as typed and any other key goes back to editing.
+func (e *Terminal) suggest() (string, bool, error) {
+	l := string(e.Buffer)
+	if e.Suggest == nil {
+		return l, true, nil
+	}
+	alts := e.Suggest(l)
+	if len(alts) == 0 {
+		return l, true, nil
+	}
+	alts = alts[:min(len(alts), 9)]
+
+	e.leaveRegion()
+	ew := &errWriter{w: e.Out}
+	ew.writeString("\n\rdid you mean?")
+	for i, a := range alts {
+		ew.writeString(fmt.Sprintf("\n\r  %d) %s", i+1, a))
+	}
+	ew.writeString(fmt.Sprintf("\n\rpick 1-%d, Enter keeps the line: ", len(alts)))
+	ew.flush()
+	if ew.err != nil {
+		return l, true, ew.err
+	}
+
+	k, err := e.readKey()
+	if err != nil {
+		return l, true, err
+	}
+	ew.writeString("\n")
+	if ew.err != nil {
+		return l, true, ew.err
+	}
+
+	done := true
+	switch {
+	case k.Code == KeyRune && k.Mod == 0 && k.Rune >= '1' && int(k.Rune-'0') <= len(alts):
+		e.Buffer = []rune(alts[k.Rune-'1'])
+		e.Cur = len(e.Buffer)
+	case k == Key{Code: KeyEnter}:
+	default:
+		done = false
+	}
+	return string(e.Buffer), done, e.refreshLine()
+}
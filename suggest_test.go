@@ -0,0 +1,60 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Joker/linenoisy/vtest"
+)
+
+func TestEditor_Suggest(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		line string
+	}{
+		{"gti status\x0d2", "git status"},
+		{"gti status\x0d\x0d", "gti status"},
+		{"gti status\x0d\x07\x01\x0b!\x0d\x0d", "!"},
+		{"git status\x0d", "git status"},
+	} {
+		e := &Terminal{
+			Inp:    bufio.NewReader(bytes.NewBufferString(tt.in)),
+			Out:    bufio.NewWriter(&bytes.Buffer{}),
+			Prompt: "> ",
+			Suggest: func(line string) []string {
+				if !strings.HasPrefix(line, "gti") {
+					return nil
+				}
+				return []string{"gt" + line[3:], "git" + line[3:]}
+			},
+		}
+		l, err := e.LineEditor()
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+		}
+		if l != tt.line {
+			t.Errorf("%q: expected %q got %q", tt.in, tt.line, l)
+		}
+	}
+}
+
+func TestEditor_SuggestScreen(t *testing.T) {
+	s := vtest.New(40, 8)
+	e := &Terminal{
+		Inp:     bufio.NewReader(bytes.NewBufferString("gti\x0d1")),
+		Out:     bufio.NewWriter(s),
+		Prompt:  "> ",
+		Cols:    40,
+		Rows:    8,
+		Suggest: func(line string) []string { return []string{"git", "gt"} },
+	}
+	if _, err := e.LineEditor(); err != nil {
+		t.Fatal(err)
+	}
+	want := "> gti\ndid you mean?\n  1) git\n  2) gt\npick 1-2, Enter keeps the line:\n> git"
+	if s.String() != want {
+		t.Errorf("expected %q got %q", want, s.String())
+	}
+}
@@ -0,0 +1,228 @@
+// Package telnet adapts a telnet connection to the io.ReadWriteCloser the line editor expects.
+//
+//	c := telnet.Wrap(conn)
+//	e := linenoisy.NewTerminal(c, "> ")
+//	c.OnResize = func(cols, rows int) { e.SetSize(cols, rows) }
+package telnet
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// https://www.rfc-editor.org/rfc/rfc854 https://www.rfc-editor.org/rfc/rfc1073
+const (
+	se   = 240
+	sb   = 250
+	will = 251
+	wont = 252
+	do   = 253
+	dont = 254
+	iac  = 255
+
+	optEcho = 1
+	optSGA  = 3
+	optNAWS = 31
+
+	maxSub = 64 // longer subnegotiations are cut, none of the supported options needs more.
+)
+
+type state int
+
+const (
+	stData state = iota
+	stIAC
+	stOpt
+	stSB
+	stSBIAC
+	stCR
+)
+
+// Conn strips the telnet protocol from the input stream, answers option negotiation
+// and escapes IAC bytes in the output stream.
+type Conn struct {
+	rwc io.ReadWriteCloser
+	wmu sync.Mutex
+
+	OnResize func(cols, rows int) // OPTIONAL; Called from Read with the NAWS window size, Terminal.SetSize can be passed on to.
+
+	st    state
+	verb  byte
+	sub   []byte
+	us    map[byte]bool // options enabled on our side.
+	them  map[byte]bool // options enabled on the client side.
+	reply []byte
+	err   error // of writing the negotiation of Wrap, returned by Read.
+}
+
+// Wrap negotiates server side echo, suppress-go-ahead and window size reporting on c.
+// An error of sending the negotiation is returned by Read.
+func Wrap(c io.ReadWriteCloser) *Conn {
+	t := &Conn{
+		rwc:  c,
+		us:   map[byte]bool{optEcho: true, optSGA: true},
+		them: map[byte]bool{optNAWS: true},
+	}
+	_, t.err = t.rwc.Write([]byte{
+		iac, will, optEcho,
+		iac, will, optSGA,
+		iac, do, optNAWS,
+	})
+	return t
+}
+
+func (t *Conn) Read(p []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	for {
+		n, err := t.rwc.Read(p)
+		n = t.filter(p[:n])
+
+		if len(t.reply) > 0 {
+			if _, werr := t.write(t.reply); werr != nil && err == nil {
+				err = werr
+			}
+			t.reply = t.reply[:0]
+		}
+
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// Write escapes IAC bytes in p.
+func (t *Conn) Write(p []byte) (int, error) {
+	buf := make([]byte, 0, len(p))
+	for _, b := range p {
+		if b == iac {
+			buf = append(buf, iac)
+		}
+		buf = append(buf, b)
+	}
+	if _, err := t.write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (t *Conn) Close() error {
+	return t.rwc.Close()
+}
+
+func (t *Conn) write(b []byte) (int, error) {
+	t.wmu.Lock()
+	defer t.wmu.Unlock()
+	return t.rwc.Write(b)
+}
+
+// filter removes protocol bytes from p in place and returns the length of the remaining data.
+func (t *Conn) filter(p []byte) int {
+	n := 0
+	for _, b := range p {
+		switch t.st {
+		case stData, stCR:
+			cr := t.st == stCR
+			t.st = stData
+			switch {
+			case b == iac:
+				t.st = stIAC
+			case cr && (b == '\n' || b == 0): // CR LF and CR NUL are a single Enter.
+			default:
+				if b == '\r' {
+					t.st = stCR
+				}
+				p[n] = b
+				n++
+			}
+		case stIAC:
+			switch b {
+			case iac:
+				p[n] = b
+				n++
+				t.st = stData
+			case will, wont, do, dont:
+				t.verb = b
+				t.st = stOpt
+			case sb:
+				t.sub = t.sub[:0]
+				t.st = stSB
+			default: // NOP, GA, AYT, ... have no meaning for a line editor.
+				t.st = stData
+			}
+		case stOpt:
+			t.negotiate(t.verb, b)
+			t.st = stData
+		case stSB:
+			if b == iac {
+				t.st = stSBIAC
+				continue
+			}
+			t.appendSub(b)
+		case stSBIAC:
+			switch b {
+			case se:
+				t.subnegotiate(t.sub)
+				t.st = stData
+			default: // IAC IAC is an escaped 255 inside the subnegotiation.
+				t.appendSub(b)
+				t.st = stSB
+			}
+		}
+	}
+	return n
+}
+
+// appendSub keeps b of a subnegotiation, up to maxSub bytes, so a client can't make it grow without IAC SE.
+func (t *Conn) appendSub(b byte) {
+	if len(t.sub) < maxSub {
+		t.sub = append(t.sub, b)
+	}
+}
+
+func (t *Conn) negotiate(verb, opt byte) {
+	switch verb {
+	case do:
+		if opt == optEcho || opt == optSGA {
+			if !t.us[opt] {
+				t.us[opt] = true
+				t.reply = append(t.reply, iac, will, opt)
+			}
+			return
+		}
+		t.reply = append(t.reply, iac, wont, opt)
+	case dont:
+		if t.us[opt] {
+			t.us[opt] = false
+			t.reply = append(t.reply, iac, wont, opt)
+		}
+	case will:
+		if opt == optNAWS || opt == optSGA {
+			if !t.them[opt] {
+				t.them[opt] = true
+				t.reply = append(t.reply, iac, do, opt)
+			}
+			return
+		}
+		t.reply = append(t.reply, iac, dont, opt)
+	case wont:
+		if t.them[opt] {
+			t.them[opt] = false
+			t.reply = append(t.reply, iac, dont, opt)
+		}
+	}
+}
+
+func (t *Conn) subnegotiate(b []byte) {
+	if len(b) != 5 || b[0] != optNAWS {
+		return
+	}
+	cols := int(binary.BigEndian.Uint16(b[1:]))
+	rows := int(binary.BigEndian.Uint16(b[3:]))
+	if cols == 0 || rows == 0 || t.OnResize == nil {
+		return
+	}
+	t.OnResize(cols, rows)
+}
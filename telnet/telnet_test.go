@@ -0,0 +1,99 @@
+package telnet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	ch := &rwc{Reader: bytes.NewReader(nil)}
+	Wrap(ch)
+
+	if got, want := ch.String(), "\xff\xfb\x01\xff\xfb\x03\xff\xfd\x1f"; got != want {
+		t.Errorf("expected %#v got %#v", want, got)
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	ch := &rwc{Reader: bytes.NewReader([]byte("a"))}
+	ch.werr = errors.New("broken pipe")
+	c := Wrap(ch)
+
+	if _, err := c.Read(make([]byte, 1)); err != ch.werr {
+		t.Errorf("expected %v got %v", ch.werr, err)
+	}
+}
+
+func TestConn_Read(t *testing.T) {
+	in := []byte("a\xff\xfd\x01\xff\xfb\x1fb\xff\xfa\x1f\x00\x64\x00\x1e\xff\xf0c\xff\xffd\xff\xfd\x18\r\n\r\x00")
+	ch := &rwc{Reader: bytes.NewReader(in)}
+	c := Wrap(ch)
+	ch.Reset()
+
+	var cols, rows int
+	c.OnResize = func(c, r int) { cols, rows = c, r }
+
+	b, err := io.ReadAll(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "abc\xffd\r\r"; got != want {
+		t.Errorf("expected %#v got %#v", want, got)
+	}
+	if cols != 100 || rows != 30 {
+		t.Errorf("expected 100x30 got %dx%d", cols, rows)
+	}
+	if got, want := ch.String(), "\xff\xfc\x18"; got != want {
+		t.Errorf("expected %#v got %#v", want, got)
+	}
+}
+
+func TestConn_ReadLongSubnegotiation(t *testing.T) {
+	in := append([]byte("a\xff\xfa\x1f"), bytes.Repeat([]byte{1}, 10000)...)
+	c := Wrap(&rwc{Reader: bytes.NewReader(append(in, "\xff\xf0b"...))})
+
+	b, err := io.ReadAll(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "ab" {
+		t.Errorf(`expected "ab" got %#v`, string(b))
+	}
+	if len(c.sub) > maxSub {
+		t.Errorf("expected at most %d bytes kept got %d", maxSub, len(c.sub))
+	}
+}
+
+func TestConn_Write(t *testing.T) {
+	ch := &rwc{Reader: bytes.NewReader(nil)}
+	c := Wrap(ch)
+	ch.Reset()
+
+	n, err := c.Write([]byte("a\xffb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 got %d", n)
+	}
+	if got, want := ch.String(), "a\xff\xffb"; got != want {
+		t.Errorf("expected %#v got %#v", want, got)
+	}
+}
+
+type rwc struct {
+	io.Reader
+	bytes.Buffer
+	werr error
+}
+
+func (c *rwc) Read(p []byte) (int, error) { return c.Reader.Read(p) }
+func (c *rwc) Write(p []byte) (int, error) {
+	if c.werr != nil {
+		return 0, c.werr
+	}
+	return c.Buffer.Write(p)
+}
+func (c *rwc) Close() error { return nil }
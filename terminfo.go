@@ -0,0 +1,115 @@
+package linenoisy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Capabilities are the control sequences the editor draws with. A cursor movement containing %d
+// is formatted with the count, otherwise it moves one cell and is repeated.
+type Capabilities struct {
+	ClearEOL    string // el
+	ClearEOS    string // ed
+	ClearLine   string // erases the whole row, the cursor column doesn't matter.
+	ClearScreen string // clear, the cursor ends at the top left.
+	Up          string // cuu or cuu1
+	Down        string // cud or cud1
+	Right       string // cuf or cuf1
+	Left        string // cub or cub1
+	NoAutoWrap  bool   // am is missing, the cursor stays in the last column instead of wrapping.
+	NoStyle     bool   // the terminal has no SGR attributes, hints and matches are drawn plain.
+}
+
+// ANSI are the capabilities of VT100 compatible terminals like xterm, screen and the Linux console.
+var ANSI = Capabilities{
+	ClearEOL:    "\x1b[0K",
+	ClearEOS:    "\x1b[0J",
+	ClearLine:   "\x1b[2K",
+	ClearScreen: "\x1b[H\x1b[2J",
+	Up:          "\x1b[%dA",
+	Down:        "\x1b[%dB",
+	Right:       "\x1b[%dC",
+	Left:        "\x1b[%dD",
+}
+
+// Terminfo maps terminal types to their capabilities, the types not listed get ANSI.
+var Terminfo = map[string]Capabilities{
+	"cons25": {
+		ClearEOL:    "\x1b[K",
+		ClearEOS:    "\x1b[J",
+		ClearLine:   "\r\x1b[K",
+		ClearScreen: "\x1b[H\x1b[J",
+		Up:          "\x1b[%dA",
+		Down:        "\x1b[%dB",
+		Right:       "\x1b[%dC",
+		Left:        "\x1b[%dD",
+	},
+	"vt52": {
+		ClearEOL:    "\x1bK",
+		ClearEOS:    "\x1bJ",
+		ClearLine:   "\r\x1bK",
+		ClearScreen: "\x1bH\x1bJ",
+		Up:          "\x1bA",
+		Down:        "\x1bB",
+		Right:       "\x1bC",
+		Left:        "\x1bD",
+		NoAutoWrap:  true,
+		NoStyle:     true,
+	},
+	"hp":     hpterm,
+	"hpterm": hpterm,
+	"hp2621": hpterm,
+	"hp2624": hpterm,
+}
+
+var hpterm = Capabilities{
+	ClearEOL:    "\x1bK",
+	ClearEOS:    "\x1bJ",
+	ClearLine:   "\r\x1bK",
+	ClearScreen: "\x1bH\x1bJ",
+	Up:          "\x1bA",
+	Down:        "\x1bB",
+	Right:       "\x1bC",
+	Left:        "\b",
+	NoStyle:     true,
+}
+
+// term returns the terminal type, Term or else TERM of a local terminal.
+func (e *Terminal) term() string {
+	if e.Term != "" {
+		return e.Term
+	}
+	if _, ok := e.Raw.(interface{ Fd() uintptr }); ok {
+		return os.Getenv("TERM")
+	}
+	return ""
+}
+
+// caps returns Caps, or else the capabilities of the terminal type.
+func (e *Terminal) caps() *Capabilities {
+	if e.Caps != nil {
+		return e.Caps
+	}
+	if c, ok := Terminfo[e.term()]; ok {
+		return &c
+	}
+	return &ANSI
+}
+
+// autoWrap reports whether the terminal moves to the next row after the last column.
+func (e *Terminal) autoWrap() bool {
+	return !e.NoAutoWrap && !e.caps().NoAutoWrap && (e.Features == nil || e.Features.AutoWrap)
+}
+
+func (c *Capabilities) up(n int) string    { return move(c.Up, n) }
+func (c *Capabilities) down(n int) string  { return move(c.Down, n) }
+func (c *Capabilities) right(n int) string { return move(c.Right, n) }
+func (c *Capabilities) left(n int) string  { return move(c.Left, n) }
+
+func move(seq string, n int) string {
+	if strings.Contains(seq, "%d") {
+		return fmt.Sprintf(seq, n)
+	}
+	return strings.Repeat(seq, n)
+}
@@ -0,0 +1,41 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEditor_Terminfo(t *testing.T) {
+	for _, tt := range []struct {
+		term, want string
+	}{
+		{"xterm", "\x1b[0K\r\x1b[3C"},
+		{"vt52", "\x1bK\r\x1bC\x1bC\x1bC"},
+		{"cons25", "\x1b[K\r\x1b[3C"},
+	} {
+		var out bytes.Buffer
+		e := &Terminal{
+			Inp:    bufio.NewReader(strings.NewReader("ab\x7f\r")),
+			Out:    bufio.NewWriter(&out),
+			Prompt: "> ",
+			Term:   tt.term,
+		}
+		if _, err := e.LineEditor(); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(out.String(), "\r> a"+tt.want) {
+			t.Errorf("%s: expected %q at the end of %q", tt.term, tt.want, out.String())
+		}
+	}
+}
+
+func TestCapabilities_Move(t *testing.T) {
+	if got := ANSI.up(3); got != "\x1b[3A" {
+		t.Errorf(`expected "\x1b[3A" got %q`, got)
+	}
+	if got := hpterm.left(2); got != "\b\b" {
+		t.Errorf(`expected "\b\b" got %q`, got)
+	}
+}
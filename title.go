@@ -0,0 +1,20 @@
+package linenoisy
+
+import "strings"
+
+// SetTitle sets the window and tab title of the terminal with OSC 2.
+// Like WriteOut, it is safe to call from other goroutines, it is written between refreshes of the line.
+// Control characters are dropped from title, so it can't end the sequence early.
+func (e *Terminal) SetTitle(title string) error {
+	title = strings.Map(func(r rune) rune {
+		if r < ' ' || (r >= 0x7f && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, title)
+
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	return e.writeSeq("\x1b]2;" + title + "\a")
+}
@@ -0,0 +1,19 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestEditor_SetTitle(t *testing.T) {
+	var out bytes.Buffer
+	e := &Terminal{Out: bufio.NewWriter(&out)}
+
+	if err := e.SetTitle("host\x1b]0;evil\a: ~"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\x1b]2;host]0;evil: ~\a"; out.String() != want {
+		t.Errorf("expected %q got %q", want, out.String())
+	}
+}
@@ -0,0 +1,47 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+func TestEditor_AdjustTTY(t *testing.T) {
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ptmx.Close()
+
+	var n, unlock uint32
+	ioctl := func(req uintptr, arg unsafe.Pointer) {
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, ptmx.Fd(), req, uintptr(arg)); errno != 0 {
+			t.Skip(errno)
+		}
+	}
+	ioctl(syscall.TIOCSPTLCK, unsafe.Pointer(&unlock))
+	ioctl(syscall.TIOCGPTN, unsafe.Pointer(&n))
+	ioctl(syscall.TIOCSWINSZ, unsafe.Pointer(&winsize{rows: 30, cols: 100}))
+
+	pts, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer pts.Close()
+
+	var out bytes.Buffer
+	e := &Terminal{Inp: bufio.NewReader(pts), Out: bufio.NewWriter(&out), Raw: pts}
+	if err := e.Adjust(); err != nil {
+		t.Fatal(err)
+	}
+	if e.Cols != 100 || e.Rows != 30 {
+		t.Errorf("expected 100x30 got %dx%d", e.Cols, e.Rows)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no queries got %q", out.String())
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly || windows)
+
+package linenoisy
+
+import "errors"
+
+func termSize(fd uintptr) (cols, rows int, err error) {
+	return 0, 0, errors.ErrUnsupported
+}
+
+func isTerminal(fd uintptr) bool {
+	return true
+}
@@ -0,0 +1,39 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package linenoisy
+
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+)
+
+type winsize struct {
+	rows, cols, xpixel, ypixel uint16
+}
+
+func getWinsize(fd uintptr) (winsize, error) {
+	var ws winsize
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return ws, errno
+	}
+	return ws, nil
+}
+
+// termSize returns the window size of the terminal fd.
+func termSize(fd uintptr) (cols, rows int, err error) {
+	ws, err := getWinsize(fd)
+	if err != nil {
+		return 0, 0, err
+	}
+	if ws.cols == 0 || ws.rows == 0 {
+		return 0, 0, errors.New("terminal size unknown")
+	}
+	return int(ws.cols), int(ws.rows), nil
+}
+
+func isTerminal(fd uintptr) bool {
+	_, err := getWinsize(fd)
+	return err == nil
+}
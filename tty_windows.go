@@ -0,0 +1,53 @@
+package linenoisy
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetConsoleScreenBufferInfo = syscall.NewLazyDLL("kernel32.dll").NewProc("GetConsoleScreenBufferInfo")
+
+type coord struct {
+	x, y int16
+}
+
+type consoleScreenBufferInfo struct {
+	size              coord
+	cursorPosition    coord
+	attributes        uint16
+	left, top         int16
+	right, bottom     int16
+	maximumWindowSize coord
+}
+
+// termSize returns the size of the console window. The screen buffer of an input handle,
+// like that of os.Stdin, is looked up through CONOUT$.
+func termSize(fd uintptr) (cols, rows int, err error) {
+	var info consoleScreenBufferInfo
+	if err := getConsoleScreenBufferInfo(syscall.Handle(fd), &info); err != nil {
+		name, _ := syscall.UTF16PtrFromString("CONOUT$")
+		h, herr := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+			syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE, nil, syscall.OPEN_EXISTING, 0, 0)
+		if herr != nil {
+			return 0, 0, err
+		}
+		defer syscall.CloseHandle(h)
+		if err := getConsoleScreenBufferInfo(h, &info); err != nil {
+			return 0, 0, err
+		}
+	}
+	return int(info.right-info.left) + 1, int(info.bottom-info.top) + 1, nil
+}
+
+func getConsoleScreenBufferInfo(h syscall.Handle, info *consoleScreenBufferInfo) error {
+	r, _, err := procGetConsoleScreenBufferInfo.Call(uintptr(h), uintptr(unsafe.Pointer(info)))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+func isTerminal(fd uintptr) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fd), &mode) == nil
+}
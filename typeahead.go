@@ -0,0 +1,41 @@
+package linenoisy
+
+import "time"
+
+// outputBurst reports whether WriteOut printed within TypeaheadHold, so more output may follow.
+func (e *Terminal) outputBurst() bool {
+	return e.TypeaheadHold > 0 && time.Since(e.lastOut) < e.TypeaheadHold
+}
+
+// holdRefresh arms the redraw of the keys typed during an output burst for when it is over.
+func (e *Terminal) holdRefresh() {
+	if e.holdTimer != nil {
+		return
+	}
+	e.holdTimer = time.AfterFunc(e.TypeaheadHold-time.Since(e.lastOut), e.releaseHold)
+}
+
+// releaseHold draws the keys held back by the output burst, unless the output goes on.
+func (e *Terminal) releaseHold() {
+	e.edit.Lock()
+	defer e.edit.Unlock()
+
+	e.holdTimer = nil
+	if !e.active || e.suspended {
+		return
+	}
+	if e.outputBurst() {
+		e.holdRefresh()
+		return
+	}
+	// an error of the terminal is reported by the next key
+	e.flushRefresh()
+}
+
+// stopHold drops a pending redraw of held keys.
+func (e *Terminal) stopHold() {
+	if e.holdTimer != nil {
+		e.holdTimer.Stop()
+		e.holdTimer = nil
+	}
+}
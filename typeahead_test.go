@@ -0,0 +1,60 @@
+package linenoisy
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEditor_TypeaheadHold(t *testing.T) {
+	r, w := io.Pipe()
+	out := &lockedBuffer{}
+	e := &Terminal{
+		Inp:           bufio.NewReader(r),
+		Out:           bufio.NewWriter(out),
+		Prompt:        "> ",
+		TypeaheadHold: 200 * time.Millisecond,
+	}
+	line := func() string {
+		e.edit.Lock()
+		defer e.edit.Unlock()
+		return string(e.Buffer)
+	}
+
+	done := make(chan string)
+	go func() {
+		l, _ := e.LineEditor()
+		done <- l
+	}()
+	waitFor(t, "the prompt", func() bool { return strings.Contains(out.String(), "> ") })
+
+	if _, err := e.WriteOut([]byte("one")); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("ab"))
+	waitFor(t, "the keys", func() bool { return line() == "ab" })
+	if strings.Contains(out.String(), "> a") {
+		t.Errorf("expected the keys held back in %q", out.String())
+	}
+
+	if _, err := e.WriteOut([]byte("two")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "two\r\n\r> ab") {
+		t.Errorf("expected the output to draw the keys in %q", out.String())
+	}
+
+	w.Write([]byte("c"))
+	waitFor(t, "the key", func() bool { return line() == "abc" })
+	if strings.Contains(out.String(), "> abc") {
+		t.Errorf("expected the key held back in %q", out.String())
+	}
+	waitFor(t, "the end of the burst", func() bool { return strings.Contains(out.String(), "> abc") })
+
+	w.Write([]byte("\r"))
+	if l := <-done; l != "abc" {
+		t.Errorf(`expected "abc" got %#v`, l)
+	}
+}
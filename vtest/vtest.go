@@ -0,0 +1,298 @@
+// Package vtest is a small VT100 emulator for tests of line editors.
+// It renders the output of the editor, so tests can assert what the user sees
+// instead of the exact escape sequences:
+//
+//	s := vtest.New(80, 24)
+//	e := &linenoisy.Terminal{Out: bufio.NewWriter(s), ...}
+//	...
+//	if s.Line(0) != "> foo bar" { ... }
+//	if col, row := s.Cursor(); col != 9 || row != 0 { ... }
+package vtest
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Joker/linenoisy/internal/wcwidth"
+)
+
+// Screen keeps the cells and the cursor of an emulated terminal. It implements io.Writer.
+//
+// Supported are printable text with autowrap, CR, LF, BS, TAB, ESC 7/8 and the CSI sequences
+// CUU, CUD, CUF, CUB, CHA, CUP, ED, EL, DECSTBM and DECAWM; other sequences, OSC and DCS strings up to
+// BEL or ST included, are consumed and ignored. East Asian Wide and Fullwidth characters and emoji take
+// two cells, combining marks and format characters join the character before them.
+type Screen struct {
+	cols, rows int
+	cells      [][]string // a wide character is followed by an empty cell.
+	x, y       int
+	wrap       bool // the cursor is past the last column, the next character wraps.
+	noWrap     bool // autowrap is off (DECAWM reset), characters past the last column overwrite it.
+
+	savedX, savedY int
+	top, bottom    int // the scroll region, set by DECSTBM.
+
+	pending []byte // incomplete UTF-8 sequence or escape sequence of the last Write.
+}
+
+// New returns a blank screen of cols x rows with the cursor at the top left corner.
+func New(cols, rows int) *Screen {
+	s := &Screen{cols: cols, rows: rows, cells: make([][]string, rows), bottom: rows - 1}
+	for i := range s.cells {
+		s.cells[i] = blank(cols)
+	}
+	return s
+}
+
+func blank(n int) []string {
+	cells := make([]string, n)
+	for i := range cells {
+		cells[i] = " "
+	}
+	return cells
+}
+
+// Write interprets p. Sequences split over several writes are completed by the following ones.
+func (s *Screen) Write(p []byte) (int, error) {
+	b := append(s.pending, p...)
+	s.pending = nil
+
+	for len(b) > 0 {
+		n := s.step(b)
+		if n == 0 {
+			s.pending = append([]byte(nil), b...)
+			break
+		}
+		b = b[n:]
+	}
+	return len(p), nil
+}
+
+// step interprets the beginning of b and returns the number of consumed bytes, 0 if b is incomplete.
+func (s *Screen) step(b []byte) int {
+	switch b[0] {
+	case '\r':
+		s.x, s.wrap = 0, false
+	case '\n':
+		s.lineFeed()
+	case '\b':
+		if s.x > 0 {
+			s.x--
+		}
+		s.wrap = false
+	case '\t':
+		s.x = min((s.x/8+1)*8, s.cols-1)
+	case 0x1b:
+		return s.escape(b)
+	default:
+		if b[0] < ' ' || b[0] == 0x7f {
+			return 1 // BEL and other controls
+		}
+		if !utf8.FullRune(b) {
+			return 0
+		}
+		r, n := utf8.DecodeRune(b)
+		s.put(r)
+		return n
+	}
+	return 1
+}
+
+func (s *Screen) put(r rune) {
+	w := wcwidth.Rune(r)
+	if w == 0 {
+		s.combine(r)
+		return
+	}
+	if s.wrap || (w == 2 && s.x == s.cols-1 && !s.noWrap) {
+		s.x = 0
+		s.lineFeed()
+	}
+	if w == 2 && s.x == s.cols-1 {
+		w = 1 // no room for the second cell without autowrap
+	}
+
+	s.clear(s.y, s.x, s.x+w)
+	s.cells[s.y][s.x] = string(r)
+	if w == 2 {
+		s.cells[s.y][s.x+1] = ""
+	}
+	if s.x+w > s.cols-1 {
+		s.wrap = !s.noWrap
+		return
+	}
+	s.x += w
+}
+
+// combine appends the zero width r to the character before the cursor.
+func (s *Screen) combine(r rune) {
+	x := s.x - 1
+	if s.wrap {
+		x = s.x
+	}
+	if x > 0 && s.cells[s.y][x] == "" {
+		x--
+	}
+	if x >= 0 {
+		s.cells[s.y][x] += string(r)
+	}
+}
+
+func (s *Screen) lineFeed() {
+	s.wrap = false
+	if s.y != s.bottom {
+		s.y = min(s.y+1, s.rows-1)
+		return
+	}
+	copy(s.cells[s.top:], s.cells[s.top+1:s.bottom+1])
+	s.cells[s.bottom] = blank(s.cols)
+}
+
+func (s *Screen) escape(b []byte) int {
+	if len(b) < 2 {
+		return 0
+	}
+	switch b[1] {
+	case '7':
+		s.savedX, s.savedY = s.x, s.y
+		return 2
+	case '8':
+		s.x, s.y, s.wrap = s.savedX, s.savedY, false
+		return 2
+	case '[':
+	case ']', 'P', '_', '^':
+		return s.str(b)
+	default:
+		return 2
+	}
+
+	for i := 2; i < len(b); i++ {
+		if b[i] >= 0x40 && b[i] <= 0x7e {
+			s.csi(string(b[2:i]), b[i])
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// str consumes an OSC, DCS, APC or PM string, which ends with BEL or ST (ESC \\).
+func (s *Screen) str(b []byte) int {
+	for i := 2; i < len(b); i++ {
+		switch {
+		case b[i] == '\a':
+			return i + 1
+		case b[i] == 0x1b && i+1 < len(b):
+			return i + 2
+		}
+	}
+	return 0
+}
+
+func (s *Screen) csi(params string, final byte) {
+	if strings.HasPrefix(params, "?") {
+		if params == "?7" && (final == 'h' || final == 'l') {
+			s.noWrap = final == 'l'
+		}
+		return // other private modes
+	}
+
+	var ps []int
+	for _, p := range strings.Split(params, ";") {
+		n, _ := strconv.Atoi(p)
+		ps = append(ps, n)
+	}
+	arg := func(i, def int) int {
+		if i < len(ps) && ps[i] > 0 {
+			return ps[i]
+		}
+		return def
+	}
+
+	s.wrap = false
+	switch final {
+	case 'A':
+		s.y = max(s.y-arg(0, 1), 0)
+	case 'B':
+		s.y = min(s.y+arg(0, 1), s.rows-1)
+	case 'C':
+		s.x = min(s.x+arg(0, 1), s.cols-1)
+	case 'D':
+		s.x = max(s.x-arg(0, 1), 0)
+	case 'G':
+		s.x = min(arg(0, 1), s.cols) - 1
+	case 'H', 'f':
+		s.y = min(arg(0, 1), s.rows) - 1
+		s.x = min(arg(1, 1), s.cols) - 1
+	case 'J':
+		switch arg(0, 0) {
+		case 0:
+			s.clear(s.y, s.x, s.cols)
+			for y := s.y + 1; y < s.rows; y++ {
+				s.clear(y, 0, s.cols)
+			}
+		case 1:
+			for y := 0; y < s.y; y++ {
+				s.clear(y, 0, s.cols)
+			}
+			s.clear(s.y, 0, s.x+1)
+		case 2, 3:
+			for y := range s.rows {
+				s.clear(y, 0, s.cols)
+			}
+		}
+	case 'r':
+		s.top, s.bottom = arg(0, 1)-1, min(arg(1, s.rows), s.rows)-1
+		if s.top >= s.bottom {
+			s.top, s.bottom = 0, s.rows-1
+		}
+		s.x, s.y = 0, 0
+	case 'K':
+		switch arg(0, 0) {
+		case 0:
+			s.clear(s.y, s.x, s.cols)
+		case 1:
+			s.clear(s.y, 0, s.x+1)
+		case 2:
+			s.clear(s.y, 0, s.cols)
+		}
+	}
+}
+
+// clear blanks the cells [from, to) of row y, and the halves of wide characters cut by them.
+func (s *Screen) clear(y, from, to int) {
+	row := s.cells[y]
+	if from > 0 && from < len(row) && row[from] == "" {
+		row[from-1] = " "
+	}
+	if to < len(row) && row[to] == "" {
+		row[to] = " "
+	}
+	for x := from; x < to; x++ {
+		row[x] = " "
+	}
+}
+
+// Line returns row y without trailing blanks.
+func (s *Screen) Line(y int) string {
+	return strings.TrimRight(strings.Join(s.cells[y], ""), " ")
+}
+
+// Lines returns all rows without trailing blanks.
+func (s *Screen) Lines() []string {
+	ls := make([]string, s.rows)
+	for y := range ls {
+		ls[y] = s.Line(y)
+	}
+	return ls
+}
+
+// String returns the rows up to the last non-blank one, joined by newlines.
+func (s *Screen) String() string {
+	return strings.TrimRight(strings.Join(s.Lines(), "\n"), "\n")
+}
+
+// Cursor returns the zero based column and row of the cursor.
+func (s *Screen) Cursor() (col, row int) {
+	return s.x, s.y
+}
@@ -0,0 +1,58 @@
+package vtest
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScreen(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		lines    []string
+		col, row int
+	}{
+		{"text", "> foo bar", []string{"> foo bar", "", ""}, 9, 0},
+		{"crlf", "foo\r\nbar", []string{"foo", "bar", ""}, 3, 1},
+		{"erase line", "foo bar\r\x1b[0Kbaz", []string{"baz", "", ""}, 3, 0},
+		{"cursor forward", "foo bar\r\x1b[4C", []string{"foo bar", "", ""}, 4, 0},
+		{"autowrap", "abcdefghijklm", []string{"abcdefghij", "klm", ""}, 3, 1},
+		{"pending wrap", "abcdefghij", []string{"abcdefghij", "", ""}, 9, 0},
+		{"no autowrap", "\x1b[?7labcdefghijklm", []string{"abcdefghim", "", ""}, 9, 0},
+		{"scroll", "a\r\nb\r\nc\r\nd", []string{"b", "c", "d"}, 1, 2},
+		{"up and erase below", "a\r\nb\r\nc\x1b[2A\r\x1b[0J", []string{"", "", ""}, 0, 0},
+		{"position", "\x1b[2;3Hx", []string{"", "  x", ""}, 3, 1},
+		{"scroll region", "\x1b[3;1Hz\x1b[1;2ra\r\nb\r\nc", []string{"b", "c", "z"}, 1, 1},
+		{"save restore", "ab\x1b7\r\ncd\x1b8e", []string{"abe", "cd", ""}, 3, 0},
+		{"utf8", "ж€", []string{"ж€", "", ""}, 2, 0},
+		{"ignored", "\x1b[?2004h\x1b[1;32mok\x1b[0m\x07", []string{"ok", "", ""}, 2, 0},
+		{"osc bel", "\x1b]0;title\x07> a", []string{"> a", "", ""}, 3, 0},
+		{"osc st", "\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\", []string{"link", "", ""}, 4, 0},
+		{"wide", "日本x", []string{"日本x", "", ""}, 5, 0},
+		{"wide at the edge", "abcdefghi日", []string{"abcdefghi", "日", ""}, 2, 1},
+		{"wide overwritten", "日本\r\x1b[1Cx", []string{" x本", "", ""}, 2, 0},
+		{"emoji", "\u231a\u2705\U0001f680x", []string{"\u231a\u2705\U0001f680x", "", ""}, 7, 0},
+		{"combining", "e\u0301x", []string{"e\u0301x", "", ""}, 2, 0},
+	}
+	for _, tt := range tests {
+		s := New(10, 3)
+		s.Write([]byte(tt.in))
+		if got := s.Lines(); !slices.Equal(got, tt.lines) {
+			t.Errorf("%s: expected %q got %q", tt.name, tt.lines, got)
+		}
+		if col, row := s.Cursor(); col != tt.col || row != tt.row {
+			t.Errorf("%s: expected cursor %d,%d got %d,%d", tt.name, tt.col, tt.row, col, row)
+		}
+	}
+}
+
+func TestScreen_SplitWrites(t *testing.T) {
+	s := New(10, 2)
+	in := []byte("ж\x1b]0;t\x07\x1b[3D\x1b[0Kx")
+	for i := range in {
+		s.Write(in[i : i+1])
+	}
+	if s.String() != "x" {
+		t.Errorf(`expected "x" got %q`, s.String())
+	}
+}
@@ -0,0 +1,12 @@
+package linenoisy
+
+import "github.com/Joker/linenoisy/internal/wcwidth"
+
+// defaultWidth is the WidthChar used when none is set: East Asian Wide and Fullwidth
+// characters and emoji take 2 columns, combining marks, format and control characters none.
+func defaultWidth(r rune) int {
+	if r == tab {
+		return 4
+	}
+	return wcwidth.Rune(r)
+}
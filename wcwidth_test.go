@@ -0,0 +1,101 @@
+package linenoisy
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDefaultWidth(t *testing.T) {
+	tests := []struct {
+		r rune
+		w int
+	}{
+		{'a', 1},
+		{'é', 1},
+		{'\t', 4},
+		{'\x01', 0},
+		{'\u0301', 0},
+		{'\u200d', 0},
+		{'\u1161', 0},
+		{'中', 2},
+		{'한', 2},
+		{'\uff21', 2},
+		{'\u3000', 2},
+		{'😀', 2},
+		{'❤', 1},
+		{'\U00020000', 2},
+	}
+	for _, tt := range tests {
+		if w := defaultWidth(tt.r); w != tt.w {
+			t.Errorf("%U: expected %d got %d", tt.r, tt.w, w)
+		}
+	}
+}
+
+func TestEditor_RunesWidth(t *testing.T) {
+	e := &Terminal{}
+	tests := []struct {
+		s string
+		w int
+	}{
+		{"abc", 3},
+		{"e\u0301", 1},
+		{"中文", 4},
+		{"👩\u200d👩\u200d👧", 2},
+		{"❤\ufe0f", 2},
+		{"🇯🇵", 2},
+	}
+	for _, tt := range tests {
+		if w := e.runesWidth([]rune(tt.s)); w != tt.w {
+			t.Errorf("%q: expected %d got %d", tt.s, tt.w, w)
+		}
+	}
+}
+
+func TestEditor_Width(t *testing.T) {
+	e := &Terminal{}
+	tests := []struct {
+		s string
+		w int
+	}{
+		{"\x1b[36mhost\x1b[0m > ", 7},
+		{"\x1b]8;;https://example.com\x1b\\docs\x1b]8;;\x1b\\> ", 6},
+		{"\x1b]2;title\a中> ", 4},
+	}
+	for _, tt := range tests {
+		if w := e.width(tt.s); w != tt.w {
+			t.Errorf("%q: expected %d got %d", tt.s, tt.w, w)
+		}
+	}
+}
+
+func TestEditor_LineWidePromptStyledHint(t *testing.T) {
+	for _, cont := range []string{"", ". "} {
+		var out bytes.Buffer
+		e := &Terminal{
+			Inp:        bufio.NewReader(bytes.NewBufferString("abcde\x02")),
+			Out:        bufio.NewWriter(&out),
+			Prompt:     "\x1b[1m日本\x1b[0m> ",
+			ContPrompt: cont,
+			Cols:       13,
+			Rows:       4,
+			Hint: func(string) string {
+				return "\x1b[2m x\x1b[0m"
+			},
+		}
+
+		if _, err := e.LineEditor(); err != io.EOF {
+			t.Fatalf("expected EOF got %v", err)
+		}
+		// prompt, input and hint fill exactly one row
+		if s := out.String(); strings.Contains(s, "\r\n") || strings.Contains(s, "\x1b[1A") {
+			t.Errorf("%q: unexpected second row in %q", cont, s)
+		}
+		if s := out.String(); !strings.HasSuffix(s, "\r\x1b[10C") {
+			t.Errorf("%q: expected cursor at column 10 in %q", cont, s)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package linenoisy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// width returns the number of terminal columns s occupies,
+// measured with WidthChar per grapheme cluster and skipping escape sequences.
+func (e *Terminal) width(s string) int {
+	return e.runesWidth([]rune(stripEscapes(s)))
+}
+
+// stripEscapes removes escape sequences, like the colors or hyperlinks of a prompt, from s.
+func stripEscapes(s string) string {
+	rs := []rune(s)
+	var sb strings.Builder
+	for i := 0; i < len(rs); {
+		if rs[i] == '\x1b' {
+			i = escapeEnd(rs, i)
+			continue
+		}
+		sb.WriteRune(rs[i])
+		i++
+	}
+	return sb.String()
+}
+
+// escapeEnd returns the end of the escape sequence starting at rs[i]. An OSC sequence, like the title
+// or a hyperlink, ends with BEL or ESC \, the others with a letter.
+func escapeEnd(rs []rune, i int) int {
+	if i+1 < len(rs) && rs[i+1] == ']' {
+		for j := i + 2; j < len(rs); j++ {
+			switch {
+			case rs[j] == '\a':
+				return j + 1
+			case rs[j] == '\x1b' && j+1 < len(rs) && rs[j+1] == '\\':
+				return j + 2
+			}
+		}
+		return len(rs)
+	}
+	j := i + 1
+	for j < len(rs) && !unicode.IsLetter(rs[j]) {
+		j++
+	}
+	return min(j+1, len(rs))
+}
+
+// truncate cuts s to at most w columns, marking the cut with an ellipsis.
+// Escape sequences are kept and take no room.
+func (e *Terminal) truncate(s string, w int) string {
+	if e.width(s) <= w {
+		return s
+	}
+
+	rs := []rune(s)
+	n, styled := 0, false
+	for i := 0; i < len(rs); {
+		if rs[i] == '\x1b' {
+			i, styled = escapeEnd(rs, i), true
+			continue
+		}
+
+		end := graphemeEnd(rs, i)
+		cw := e.runesWidth(rs[i:end])
+		if n+cw > w-1 {
+			if styled {
+				return string(rs[:i]) + "…" + string(Reset)
+			}
+			return string(rs[:i]) + "…"
+		}
+		n += cw
+		i = end
+	}
+	return s
+}
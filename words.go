@@ -0,0 +1,66 @@
+package linenoisy
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultWordBreakChars separate the word CompleteWord completes unless WordBreakChars is set: whitespace and the
+// quotes and shell operators readline breaks words at, so paths and options like "-rf" are completed as a whole.
+const DefaultWordBreakChars = " \t\n\"'`@$><=;|&{("
+
+// wordBreak reports whether r separates words for the word motions, Alt-Backspace and Alt-d: a rune of
+// WordBreakChars if set, else any rune but a letter, a digit or '_', so "/tmp/x" is three words like in readline.
+func (e *Terminal) wordBreak(r rune) bool {
+	if e.WordBreakChars != "" {
+		return strings.ContainsRune(e.WordBreakChars, r)
+	}
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+// completionBreak reports whether r separates the word CompleteWord completes.
+func (e *Terminal) completionBreak(r rune) bool {
+	chars := e.WordBreakChars
+	if chars == "" {
+		chars = DefaultWordBreakChars
+	}
+	return strings.ContainsRune(chars, r)
+}
+
+// editBackwardKillWord kills from the start of the word before the cursor, on Alt-Backspace.
+func (e *Terminal) editBackwardKillWord() error {
+	p := e.Cur
+	for p > 0 && e.wordBreak(e.Buffer[p-1]) {
+		p--
+	}
+	for p > 0 && !e.wordBreak(e.Buffer[p-1]) {
+		p--
+	}
+	if p == e.Cur {
+		return e.beep()
+	}
+
+	e.kill(e.Buffer[p:e.Cur])
+	e.Buffer = slices.Delete(e.Buffer, p, e.Cur)
+	e.Cur = p
+	return e.refreshLine()
+}
+
+// editDeleteNextWord kills up to the end of the word after the cursor, on Alt-d.
+func (e *Terminal) editDeleteNextWord() error {
+	p := e.Cur
+	for p < len(e.Buffer) && e.wordBreak(e.Buffer[p]) {
+		p++
+	}
+	for p < len(e.Buffer) && !e.wordBreak(e.Buffer[p]) {
+		p++
+	}
+	if p == e.Cur {
+		return e.beep()
+	}
+
+	e.kill(e.Buffer[e.Cur:p])
+	e.Buffer = slices.Delete(e.Buffer, e.Cur, p)
+	return e.refreshLine()
+}